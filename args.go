@@ -0,0 +1,182 @@
+package logger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BadKey is the attribute key used for trailing arguments that can't be paired
+// with a string key.
+const BadKey = "!BADKEY"
+
+// formatArgs formats msg with the printf args among args and collects the rest
+// as attributes. See splitArgs.
+func formatArgs(msg string, args []interface{}) (string, *Attrs) {
+	v, attrs := splitArgs(msg, args)
+	return formatMessage(msg, v), attrs
+}
+
+// splitArgs separates the printf args of msg from its attributes. Attrs, Field
+// and slog.Attr values are attributes wherever they appear. Of the other args, as
+// many as msg has verbs are printf args; the ones left over are read as key/value
+// pairs or maps of attributes.
+func splitArgs(msg string, args []interface{}) ([]interface{}, *Attrs) {
+	var attrs Attrs
+	var v, extra []interface{}
+	verbs := countVerbs(msg)
+
+	for _, arg := range args {
+		if a, ok := arg.(Attrs); ok {
+			attrs = mergeAttrs(attrs, a)
+			continue
+		}
+
+		if field, ok := arg.(Field); ok {
+			if field.Key != "" {
+				attrs = mergeAttrs(attrs, nil)
+				attrs[field.Key] = field.Value()
+			}
+			continue
+		}
+
+		if a, ok := slogAttrs(arg); ok {
+			attrs = mergeAttrs(attrs, a)
+			continue
+		}
+
+		if len(v) < verbs {
+			v = append(v, arg)
+		} else {
+			extra = append(extra, arg)
+		}
+	}
+
+	for i := 0; i < len(extra); i++ {
+		switch arg := extra[i].(type) {
+		case map[string]interface{}:
+			attrs = mergeAttrs(attrs, arg)
+		case string:
+			if i+1 < len(extra) {
+				attrs = mergeAttrs(attrs, Attrs{arg: extra[i+1]})
+				i++
+			} else {
+				attrs = mergeAttrs(attrs, Attrs{BadKey: arg})
+			}
+		default:
+			attrs = mergeAttrs(attrs, Attrs{BadKey: arg})
+		}
+	}
+
+	if attrs == nil {
+		return v, nil
+	}
+
+	return v, &attrs
+}
+
+func mergeAttrs(attrs Attrs, add map[string]interface{}) Attrs {
+	if attrs == nil {
+		attrs = make(Attrs, len(add))
+	}
+
+	for key, val := range add {
+		attrs[key] = val
+	}
+
+	return attrs
+}
+
+// countVerbs returns the number of args the printf format consumes, including
+// "*" widths and precisions and explicit argument indexes. "%%" consumes none.
+func countVerbs(format string) int {
+	count, next := 0, 0
+
+	consume := func() {
+		next++
+		if next > count {
+			count = next
+		}
+	}
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+
+		for i++; i < len(format); i++ {
+			c := format[i]
+
+			switch {
+			case c == '%' && format[i-1] == '%':
+			case c == '+' || c == '-' || c == '#' || c == ' ' || c == '0' || c == '.':
+				continue
+			case c >= '1' && c <= '9':
+				continue
+			case c == '*':
+				consume()
+				continue
+			case c == '[':
+				end := i + 1
+				for end < len(format) && format[end] != ']' {
+					end++
+				}
+				if n, err := strconv.Atoi(format[i+1 : end]); err == nil && n > 0 {
+					next = n - 1
+				}
+				i = end
+				continue
+			default:
+				consume()
+			}
+
+			break
+		}
+	}
+
+	return count
+}
+
+// interpolate replaces the "{key}" placeholders of message with the values of the
+// matching attributes. Braces that don't name an attribute are left as they are.
+func interpolate(message string, attrs *Attrs) string {
+	if attrs == nil || !strings.Contains(message, "{") {
+		return message
+	}
+
+	var b strings.Builder
+
+	for {
+		start := strings.IndexByte(message, '{')
+		if start < 0 {
+			break
+		}
+
+		end := strings.IndexByte(message[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+
+		// Restart from an inner brace, so that "{{key}" still matches.
+		if inner := strings.LastIndexByte(message[start:end], '{'); inner > 0 {
+			b.WriteString(message[:start+inner])
+			message = message[start+inner:]
+			continue
+		}
+
+		val, ok := (*attrs)[message[start+1:end]]
+		if !ok {
+			b.WriteString(message[:end+1])
+			message = message[end+1:]
+			continue
+		}
+
+		b.WriteString(message[:start])
+		fmt.Fprint(&b, val)
+		message = message[end+1:]
+	}
+
+	b.WriteString(message)
+	return b.String()
+}
@@ -0,0 +1,46 @@
+package logger
+
+import "testing"
+
+func TestCountVerbs(t *testing.T) {
+	tests := []struct {
+		format string
+		want   int
+	}{
+		{"", 0},
+		{"no verbs", 0},
+		{"%d", 1},
+		{"%s and %v", 2},
+		{"100%%", 0},
+		{"%d%%", 1},
+		{"%%%d", 1},
+		{"%5.2f", 1},
+		{"%-#+08x", 1},
+		{"%*d", 2},
+		{"%-*.*f", 3},
+		{"%[1]d %[1]d", 1},
+		{"%[2]d %[1]d", 2},
+		{"%[3]*.[2]*[1]f", 3},
+		{"%[2]d %d", 3},
+		{"trailing %", 0},
+		{"%d trailing %", 1},
+	}
+
+	for _, test := range tests {
+		if got := countVerbs(test.format); got != test.want {
+			t.Errorf("countVerbs(%q) = %d, want %d", test.format, got, test.want)
+		}
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	v, attrs := splitArgs("%d%% of %s", []interface{}{50, "disk", "host", "db1", Attrs{"job": 1}})
+
+	if len(v) != 2 || v[0] != 50 || v[1] != "disk" {
+		t.Errorf("printf args = %v, want [50 disk]", v)
+	}
+
+	if attrs == nil || len(*attrs) != 2 || (*attrs)["host"] != "db1" || (*attrs)["job"] != 1 {
+		t.Errorf("attrs = %v, want host and job", attrs)
+	}
+}
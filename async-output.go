@@ -0,0 +1,195 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// AsyncOptions configures an AsyncWriter.
+type AsyncOptions struct {
+	// BufferSize is the number of logs that can be queued before Write blocks.
+	// Defaults to 1024.
+	BufferSize int
+
+	// DrainTimeout bounds how long queued logs are still written after the
+	// context is cancelled. Defaults to one second.
+	DrainTimeout time.Duration
+}
+
+// AsyncWriter hands logs over to a background goroutine that writes them to an
+// inner writer, so that slow outputs don't hold up the program.
+type AsyncWriter struct {
+	inner   OutputWriter
+	queue   chan asyncItem
+	closing chan struct{}
+	done    chan struct{}
+	timeout time.Duration
+
+	// mutex guards closed, so that no log is queued once the queue is drained.
+	mutex  sync.RWMutex
+	closed bool
+}
+
+type asyncItem struct {
+	log     *Log
+	swap    OutputWriter
+	flushed chan struct{}
+}
+
+// NewAsyncWriter starts an AsyncWriter bound to ctx. Once ctx is cancelled, the
+// queue is drained into inner until the drain timeout, then inner is flushed and
+// closed (if it implements Flusher or io.Closer) and the goroutine exits.
+func NewAsyncWriter(ctx context.Context, inner OutputWriter, opts AsyncOptions) *AsyncWriter {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 1024
+	}
+
+	if opts.DrainTimeout <= 0 {
+		opts.DrainTimeout = time.Second
+	}
+
+	asyncWriter := &AsyncWriter{
+		inner:   inner,
+		queue:   make(chan asyncItem, opts.BufferSize),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+		timeout: opts.DrainTimeout,
+	}
+
+	go asyncWriter.run(ctx)
+
+	return asyncWriter
+}
+
+func (asyncWriter *AsyncWriter) Init() {
+	asyncWriter.inner.Init()
+}
+
+// Write queues a copy of the log. Logs written once ctx is cancelled are dropped.
+func (asyncWriter *AsyncWriter) Write(log *Log) {
+	asyncWriter.send(asyncItem{log: log.Clone()})
+}
+
+// Flush blocks until every log queued so far has been written and the inner
+// writer has been flushed.
+func (asyncWriter *AsyncWriter) Flush() error {
+	flushed := make(chan struct{})
+	if !asyncWriter.send(asyncItem{flushed: flushed}) {
+		return nil
+	}
+
+	select {
+	case <-flushed:
+	case <-asyncWriter.done:
+	}
+
+	return nil
+}
+
+// Reconfigure replaces the inner writer, e.g. with one using a new format or
+// levels. The logs queued so far are still written and flushed to the current
+// inner writer, which isn't closed, so that none is lost or written twice. It
+// returns once the swap is done. The new writer is initialized by the background
+// goroutine, so it isn't once ctx is cancelled.
+func (asyncWriter *AsyncWriter) Reconfigure(inner OutputWriter) {
+	flushed := make(chan struct{})
+	if !asyncWriter.send(asyncItem{swap: inner, flushed: flushed}) {
+		return
+	}
+
+	select {
+	case <-flushed:
+	case <-asyncWriter.done:
+	}
+}
+
+// Done is closed once the background goroutine has drained and closed the inner
+// writer after cancellation.
+func (asyncWriter *AsyncWriter) Done() <-chan struct{} {
+	return asyncWriter.done
+}
+
+func (asyncWriter *AsyncWriter) send(item asyncItem) bool {
+	asyncWriter.mutex.RLock()
+	defer asyncWriter.mutex.RUnlock()
+
+	if asyncWriter.closed {
+		return false
+	}
+
+	select {
+	case asyncWriter.queue <- item:
+		return true
+	case <-asyncWriter.closing:
+		return false
+	}
+}
+
+func (asyncWriter *AsyncWriter) run(ctx context.Context) {
+	defer close(asyncWriter.done)
+
+	for {
+		select {
+		case item := <-asyncWriter.queue:
+			asyncWriter.handle(item)
+		case <-ctx.Done():
+			// Wake up the senders blocked on a full queue, then wait for
+			// them to be done before draining.
+			close(asyncWriter.closing)
+			asyncWriter.mutex.Lock()
+			asyncWriter.closed = true
+			asyncWriter.mutex.Unlock()
+
+			asyncWriter.drain()
+			asyncWriter.shutdown()
+			return
+		}
+	}
+}
+
+func (asyncWriter *AsyncWriter) handle(item asyncItem) {
+	if item.log != nil {
+		asyncWriter.inner.Write(item.log)
+	}
+
+	if item.flushed != nil {
+		if flusher, ok := asyncWriter.inner.(Flusher); ok {
+			flusher.Flush()
+		}
+	}
+
+	if item.swap != nil {
+		item.swap.Init()
+		asyncWriter.inner = item.swap
+	}
+
+	if item.flushed != nil {
+		close(item.flushed)
+	}
+}
+
+func (asyncWriter *AsyncWriter) drain() {
+	deadline := time.NewTimer(asyncWriter.timeout)
+	defer deadline.Stop()
+
+	for len(asyncWriter.queue) > 0 {
+		select {
+		case item := <-asyncWriter.queue:
+			asyncWriter.handle(item)
+		case <-deadline.C:
+			return
+		}
+	}
+}
+
+func (asyncWriter *AsyncWriter) shutdown() {
+	if flusher, ok := asyncWriter.inner.(Flusher); ok {
+		flusher.Flush()
+	}
+
+	if closer, ok := asyncWriter.inner.(io.Closer); ok {
+		closer.Close()
+	}
+}
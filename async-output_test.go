@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAsyncWriterDrainsOnCancel(t *testing.T) {
+	recorder := &recordWriter{}
+	ctx, cancel := context.WithCancel(context.Background())
+	asyncWriter := NewAsyncWriter(ctx, recorder, AsyncOptions{BufferSize: 100})
+
+	for i := 0; i < 50; i++ {
+		asyncWriter.Write(&Log{Message: "queued"})
+	}
+
+	cancel()
+	<-asyncWriter.Done()
+
+	asyncWriter.Write(&Log{Message: "after shutdown"})
+
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	if len(recorder.logs) != 50 {
+		t.Errorf("wrote %d logs, want the 50 queued before cancellation", len(recorder.logs))
+	}
+}
+
+// closeWriter counts the logs written to it after it's closed.
+type closeWriter struct {
+	initialized int32
+	closed      int32
+	late        int32
+}
+
+func (closeWriter *closeWriter) Init() {
+	atomic.StoreInt32(&closeWriter.initialized, 1)
+}
+
+func (closeWriter *closeWriter) Write(log *Log) {
+	if atomic.LoadInt32(&closeWriter.closed) == 1 {
+		atomic.AddInt32(&closeWriter.late, 1)
+	}
+}
+
+func (closeWriter *closeWriter) Close() error {
+	atomic.StoreInt32(&closeWriter.closed, 1)
+	return nil
+}
+
+func TestAsyncWriterRejectsLateWrites(t *testing.T) {
+	for round := 0; round < 50; round++ {
+		inner := &closeWriter{}
+		ctx, cancel := context.WithCancel(context.Background())
+		asyncWriter := NewAsyncWriter(ctx, inner, AsyncOptions{BufferSize: 4})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 200; j++ {
+					asyncWriter.Write(&Log{Message: "racing"})
+				}
+			}()
+		}
+
+		cancel()
+		wg.Wait()
+		<-asyncWriter.Done()
+
+		if late := atomic.LoadInt32(&inner.late); late > 0 {
+			t.Fatalf("round %d: %d logs were written after the inner writer was closed", round, late)
+		}
+	}
+}
+
+func TestAsyncWriterReconfigureAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	asyncWriter := NewAsyncWriter(ctx, &recordWriter{}, AsyncOptions{})
+
+	cancel()
+	<-asyncWriter.Done()
+
+	replacement := &closeWriter{}
+	asyncWriter.Reconfigure(replacement)
+
+	if atomic.LoadInt32(&replacement.initialized) == 1 {
+		t.Error("Reconfigure initialized a writer it couldn't swap in")
+	}
+}
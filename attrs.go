@@ -0,0 +1,443 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// F starts a new set of attributes, to be built with the typed setters below and
+// passed as the last argument of a log call:
+//
+//	log.Info("Fetched %s", url, logger.F().Int("status", 200).Dur("took", elapsed))
+func F() Attrs {
+	return Attrs{}
+}
+
+// Attr returns a single attribute, e.g. for a message interpolating it (see
+// Logger.Interpolate).
+func Attr(key string, val interface{}) Attrs {
+	return Attrs{key: val}
+}
+
+// Str sets a string attribute.
+func (attrs Attrs) Str(key, val string) Attrs {
+	attrs[key] = val
+	return attrs
+}
+
+// Int sets an integer attribute.
+func (attrs Attrs) Int(key string, val int) Attrs {
+	attrs[key] = val
+	return attrs
+}
+
+// Int64 sets a 64-bit integer attribute.
+func (attrs Attrs) Int64(key string, val int64) Attrs {
+	attrs[key] = val
+	return attrs
+}
+
+// Float sets a floating point attribute.
+func (attrs Attrs) Float(key string, val float64) Attrs {
+	attrs[key] = val
+	return attrs
+}
+
+// Bool sets a boolean attribute.
+func (attrs Attrs) Bool(key string, val bool) Attrs {
+	attrs[key] = val
+	return attrs
+}
+
+// Dur sets a duration attribute, rendered as e.g. "1.5s".
+func (attrs Attrs) Dur(key string, val time.Duration) Attrs {
+	attrs[key] = val
+	return attrs
+}
+
+// Time sets a time attribute, rendered with the writer's time layout.
+func (attrs Attrs) Time(key string, val time.Time) Attrs {
+	attrs[key] = val
+	return attrs
+}
+
+// Size sets a byte count attribute. See Bytes.
+func (attrs Attrs) Size(key string, val int64) Attrs {
+	attrs[key] = Bytes(val)
+	return attrs
+}
+
+// Err sets the "error" attribute, rendered with the error's message.
+func (attrs Attrs) Err(err error) Attrs {
+	attrs["error"] = err
+	return attrs
+}
+
+// Any sets an attribute of any type.
+func (attrs Attrs) Any(key string, val interface{}) Attrs {
+	attrs[key] = val
+	return attrs
+}
+
+// HighlightKey is the attribute set by Highlight.
+const HighlightKey = "highlight"
+
+// Highlight marks a log to stand out in pretty output, where its message is shown
+// in bold, without changing its level:
+//
+//	log.Info("Listening on %s", addr, logger.Highlight())
+//
+// Other formats carry it as a "highlight" attribute.
+func Highlight() Attrs {
+	return Attrs{HighlightKey: true}
+}
+
+func highlighted(attrs *Attrs) bool {
+	if attrs == nil {
+		return false
+	}
+
+	on, _ := (*attrs)[HighlightKey].(bool)
+	return on
+}
+
+// MarshalJSON encodes the attributes key by key, falling back to the "%v"
+// representation of values that can't be serialized (channels, funcs, failing
+// MarshalJSON methods) so that one bad attribute doesn't spoil the whole log.
+func (attrs Attrs) MarshalJSON() ([]byte, error) {
+	if attrs == nil {
+		return []byte("null"), nil
+	}
+
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer bufferPool.Put(buf)
+
+	writeAttrsJSON(buf, attrs)
+
+	return append([]byte(nil), buf.Bytes()...), nil
+}
+
+func marshalAttr(val interface{}) (raw json.RawMessage) {
+	defer func() {
+		if recover() != nil {
+			raw, _ = json.Marshal(PanicValue)
+		}
+	}()
+
+	raw, err := json.Marshal(val)
+	if err != nil {
+		raw, _ = json.Marshal(fmt.Sprintf("%v", val))
+	}
+
+	return raw
+}
+
+// Bytes marks an attribute value as a byte count, so that it's rendered with units
+// (e.g. 1.5MiB) in pretty output. JSON output keeps the raw number and adds a
+// sibling "<key>_human" field.
+type Bytes int64
+
+// String formats the byte count using binary units.
+func (b Bytes) String() string {
+	return b.Human(false)
+}
+
+// Human formats the byte count using decimal (kB, MB) or binary (KiB, MiB) units.
+func (b Bytes) Human(decimal bool) string {
+	unit, suffixes := int64(1024), []string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	if decimal {
+		unit, suffixes = 1000, []string{"kB", "MB", "GB", "TB", "PB", "EB"}
+	}
+
+	n := int64(b)
+	if n < 0 {
+		return "-" + Bytes(-n).Human(decimal)
+	}
+
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+
+	div, exp := unit, 0
+	for m := n / unit; m >= unit && exp < len(suffixes)-1; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f%s", float64(n)/float64(div), suffixes[exp])
+}
+
+// attrValue converts an attribute value into the form the formatters should
+// render. Durations become their Go duration string and times are formatted using
+// the writer's layout, including values nested in maps and slices. Nil pointers
+// become nil, except for errors and Stringers whose methods may handle a nil
+// receiver. Values whose String or Error method panics become "!PANIC".
+func (standardWriter *StandardWriter) attrValue(val interface{}, forJSON bool) (result interface{}) {
+	defer func() {
+		if recover() != nil {
+			result = PanicValue
+		}
+	}()
+
+	switch val.(type) {
+	case error, fmt.Stringer:
+	default:
+		if isNilPointer(val) {
+			return nil
+		}
+	}
+
+	switch v := val.(type) {
+	case float64:
+		return standardWriter.floatValue(v, 64)
+	case float32:
+		return standardWriter.floatValue(float64(v), 32)
+	case time.Duration:
+		return v.String()
+	case time.Time:
+		if forJSON && standardWriter.JSONTimeFormat != "" {
+			return v.Format(standardWriter.JSONTimeFormat)
+		}
+		if forJSON {
+			return v.Format(time.RFC3339Nano)
+		}
+		return v.Format(standardWriter.timeFormat())
+	case Bytes:
+		if forJSON {
+			return int64(v)
+		}
+		return v.Human(standardWriter.DecimalBytes)
+	case []byte:
+		return standardWriter.binaryValue(v, forJSON)
+	case error:
+		if standardWriter.VerboseErrors {
+			return fmt.Sprintf("%+v", v)
+		}
+		return v.Error()
+	case Attrs:
+		return standardWriter.attrMap(v, forJSON)
+	case map[string]interface{}:
+		return standardWriter.attrMap(v, forJSON)
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = standardWriter.attrValue(item, forJSON)
+		}
+		return result
+	case fmt.Stringer:
+		if !forJSON || standardWriter.StringerAttrs {
+			return v.String()
+		}
+	}
+
+	return val
+}
+
+// floatValue applies FloatPrecision to a float attribute.
+func (standardWriter *StandardWriter) floatValue(f float64, bits int) interface{} {
+	if standardWriter.FloatPrecision <= 0 || math.IsNaN(f) || math.IsInf(f, 0) {
+		if bits == 32 {
+			return float32(f)
+		}
+		return f
+	}
+
+	return json.Number(strconv.FormatFloat(f, 'f', standardWriter.FloatPrecision, bits))
+}
+
+// Encodings of []byte attributes accepted by StandardWriter.BinaryEncoding.
+const (
+	BinaryHex    = "hex"
+	BinaryBase64 = "base64"
+)
+
+// DefaultMaxBinaryAttr is the number of bytes of []byte attributes rendered by
+// NewStandardOutput.
+const DefaultMaxBinaryAttr = 1024
+
+// binaryValue renders a []byte attribute as base64 in JSON, as encoding/json
+// does, and following BinaryEncoding otherwise, truncated to MaxBinaryAttr.
+func (standardWriter *StandardWriter) binaryValue(b []byte, forJSON bool) string {
+	truncated := 0
+	if max := standardWriter.MaxBinaryAttr; max > 0 && len(b) > max {
+		b, truncated = b[:max], len(b)-max
+	}
+
+	if forJSON {
+		return base64.StdEncoding.EncodeToString(b)
+	}
+
+	encoded := fmt.Sprintf("%x", b)
+	if standardWriter.BinaryEncoding == BinaryBase64 {
+		encoded = base64.StdEncoding.EncodeToString(b)
+	}
+
+	if truncated > 0 {
+		encoded += fmt.Sprintf(" …(+%d bytes)", truncated)
+	}
+
+	return encoded
+}
+
+// PanicValue replaces attribute values that panic while being formatted.
+const PanicValue = "!PANIC"
+
+func isNilPointer(val interface{}) bool {
+	rv := reflect.ValueOf(val)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
+func (standardWriter *StandardWriter) attrMap(m map[string]interface{}, forJSON bool) map[string]interface{} {
+	result := make(map[string]interface{}, len(m))
+	for key, val := range m {
+		if flattened, ok := val.(Flattened); ok {
+			for field, v := range flattened.Fields(key) {
+				result[field] = standardWriter.attrValue(v, forJSON)
+			}
+			continue
+		}
+
+		result[key] = standardWriter.attrValue(val, forJSON)
+
+		if b, ok := val.(Bytes); ok && forJSON {
+			result[key+"_human"] = b.Human(standardWriter.DecimalBytes)
+		}
+
+		if b, ok := val.([]byte); ok && forJSON {
+			if max := standardWriter.MaxBinaryAttr; max > 0 && len(b) > max {
+				result[key+"_truncated"] = len(b) - max
+			}
+		}
+
+		if err, ok := val.(error); ok && standardWriter.VerboseErrors {
+			if causes := errorCauses(err); len(causes) > 0 {
+				if forJSON {
+					result[key+"_cause"] = causes
+				} else {
+					result[key+"_cause"] = strings.Join(causes, "; ")
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// errorCauses returns the messages of the errors wrapped by err, outermost first.
+func errorCauses(err error) []string {
+	var causes []string
+	for cause := errors.Unwrap(err); cause != nil; cause = errors.Unwrap(cause) {
+		causes = append(causes, cause.Error())
+	}
+
+	return causes
+}
+
+// jsonAttrs returns a copy of attrs prepared for JSON output, or nil when there
+// are none so that the field is omitted. Attributes that need no preparation are
+// returned as they are and must not be modified.
+func (standardWriter *StandardWriter) jsonAttrs(attrs *Attrs) *Attrs {
+	if attrs == nil || len(*attrs) == 0 {
+		return nil
+	}
+
+	if standardWriter.plainAttrs(*attrs) {
+		return attrs
+	}
+
+	limited, truncated := standardWriter.limitAttrs(*attrs)
+
+	result := Attrs(standardWriter.attrMap(limited, true))
+	if truncated > 0 {
+		result[AttrsTruncatedKey] = truncated
+	}
+
+	if standardWriter.ExpandDots {
+		result = expandDots(result)
+	}
+
+	return &result
+}
+
+// plainAttrs reports whether jsonAttrs would leave attrs as they are, so that
+// they needn't be copied.
+func (standardWriter *StandardWriter) plainAttrs(attrs Attrs) bool {
+	if standardWriter.ExpandDots || standardWriter.MaxAttrs > 0 && len(attrs) > standardWriter.MaxAttrs {
+		return false
+	}
+
+	for _, val := range attrs {
+		switch val.(type) {
+		case nil, string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		case float32, float64:
+			if standardWriter.FloatPrecision > 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// expandDots nests the attributes with dotted keys, e.g. "http.status" becomes
+// "status" within "http". Where a key is both a value and a prefix, like "http"
+// and "http.status", the value is kept and the longer key stays flat.
+func expandDots(attrs Attrs) Attrs {
+	result := Attrs{}
+
+	for _, key := range sortedKeys(attrs) {
+		target := map[string]interface{}(result)
+		parts := strings.Split(key, ".")
+
+		for len(parts) > 1 {
+			next, exists := target[parts[0]]
+			if !exists {
+				next = map[string]interface{}{}
+				target[parts[0]] = next
+			}
+
+			nested, ok := next.(map[string]interface{})
+			if !ok {
+				break
+			}
+
+			target = nested
+			parts = parts[1:]
+		}
+
+		target[strings.Join(parts, ".")] = attrs[key]
+	}
+
+	return result
+}
+
+// AttrsTruncatedKey holds the number of attributes left out of JSON and logfmt
+// output because of MaxAttrs.
+const AttrsTruncatedKey = "_attrs_truncated"
+
+// limitAttrs keeps the first MaxAttrs attributes in key order, returning how many
+// were left out.
+func (standardWriter *StandardWriter) limitAttrs(attrs Attrs) (Attrs, int) {
+	max := standardWriter.MaxAttrs
+	if max <= 0 || len(attrs) <= max {
+		return attrs, 0
+	}
+
+	limited := make(Attrs, max)
+	for _, key := range sortedKeys(attrs)[:max] {
+		limited[key] = attrs[key]
+	}
+
+	return limited, len(attrs) - max
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+type panickingStringer struct {
+	name string
+}
+
+func (stringer *panickingStringer) String() string {
+	return stringer.name
+}
+
+type nilSafeStringer struct{}
+
+func (stringer *nilSafeStringer) String() string {
+	if stringer == nil {
+		return "none"
+	}
+	return "some"
+}
+
+func TestAttrValueTypedNil(t *testing.T) {
+	var panicking *panickingStringer
+	var nilSafe *nilSafeStringer
+	var pointer *int
+
+	standardWriter := &StandardWriter{}
+
+	if got := standardWriter.attrValue(panicking, false); got != PanicValue {
+		t.Errorf("attrValue(nil panicking Stringer) = %v, want %s", got, PanicValue)
+	}
+
+	if got := standardWriter.attrValue(nilSafe, false); got != "none" {
+		t.Errorf("attrValue(nil-safe Stringer) = %v, want none", got)
+	}
+
+	if got := standardWriter.attrValue(pointer, false); got != nil {
+		t.Errorf("attrValue(nil *int) = %v, want nil", got)
+	}
+
+	log := &Log{Message: "typed nil", Attrs: &Attrs{"stringer": panicking, "pointer": pointer, "nil": nil}}
+
+	if output := (&StandardWriter{OutputFormat: FormatPretty}).PrettyFormat(log); !strings.Contains(output, PanicValue) {
+		t.Errorf("pretty output %q doesn't contain %s", output, PanicValue)
+	}
+
+	output := standardWriter.JSONFormat(log)
+	if !strings.Contains(output, `"stringer":null`) || !strings.Contains(output, `"pointer":null`) || !strings.Contains(output, `"nil":null`) {
+		t.Errorf("JSON output %s doesn't render the typed nils as null", output)
+	}
+
+	output = (&StandardWriter{StringerAttrs: true}).JSONFormat(log)
+	if !strings.Contains(output, `"stringer":"`+PanicValue+`"`) {
+		t.Errorf("JSON output %s with StringerAttrs doesn't contain %s", output, PanicValue)
+	}
+}
@@ -0,0 +1,140 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// AutoMuteOptions configures an AutoMuteWriter.
+type AutoMuteOptions struct {
+	// Rate is the number of logs per second a package can sustain before being
+	// muted.
+	Rate float64
+
+	// Burst is the number of logs a package can write at once above Rate.
+	// Defaults to Rate, and to at least one.
+	Burst float64
+
+	// Cooldown is how long a runaway package stays muted. Defaults to ten
+	// seconds.
+	Cooldown time.Duration
+}
+
+// AutoMuteWriter forwards logs to an inner writer, muting a package for a while
+// when it logs faster than allowed, e.g. from a tight loop. It writes a warning
+// when a package is muted, and reports how many of its logs were dropped when
+// its next log comes past the cooldown. The warnings are shown by the standard
+// and file outputs whatever their package filters.
+type AutoMuteWriter struct {
+	inner    OutputWriter
+	opts     AutoMuteOptions
+	mutex    sync.Mutex
+	packages map[string]*autoMuteBucket
+}
+
+type autoMuteBucket struct {
+	tokens     float64
+	updatedAt  int64
+	mutedUntil int64
+	dropped    int
+}
+
+// NewAutoMuteWriter wraps inner with auto-muting of runaway packages.
+func NewAutoMuteWriter(inner OutputWriter, opts AutoMuteOptions) *AutoMuteWriter {
+	if opts.Burst <= 0 {
+		opts.Burst = opts.Rate
+	}
+
+	// Below one token, no log could ever be written.
+	if opts.Burst < 1 {
+		opts.Burst = 1
+	}
+
+	if opts.Cooldown <= 0 {
+		opts.Cooldown = 10 * time.Second
+	}
+
+	return &AutoMuteWriter{
+		inner:    inner,
+		opts:     opts,
+		packages: map[string]*autoMuteBucket{},
+	}
+}
+
+func (autoMuteWriter *AutoMuteWriter) Init() {
+	autoMuteWriter.inner.Init()
+}
+
+func (autoMuteWriter *AutoMuteWriter) Write(log *Log) {
+	if autoMuteWriter.opts.Rate <= 0 {
+		autoMuteWriter.inner.Write(log)
+		return
+	}
+
+	allowed, notice := autoMuteWriter.allow(log.Package)
+
+	if notice != nil {
+		emit(autoMuteWriter.inner, notice)
+	}
+
+	if allowed {
+		autoMuteWriter.inner.Write(log)
+	}
+}
+
+// allow takes a token from the package's bucket, returning whether the log can be
+// written and the notice to write first, if the package was muted or unmuted.
+func (autoMuteWriter *AutoMuteWriter) allow(pkg string) (bool, *Log) {
+	autoMuteWriter.mutex.Lock()
+	defer autoMuteWriter.mutex.Unlock()
+
+	now := Now()
+
+	bucket, ok := autoMuteWriter.packages[pkg]
+	if !ok {
+		bucket = &autoMuteBucket{tokens: autoMuteWriter.opts.Burst, updatedAt: now}
+		autoMuteWriter.packages[pkg] = bucket
+	}
+
+	var notice *Log
+
+	if bucket.mutedUntil != 0 {
+		if now < bucket.mutedUntil {
+			bucket.dropped++
+			return false, nil
+		}
+
+		notice = autoMuteWriter.notice(pkg, "Unmuted %q, %d logs were dropped", pkg, bucket.dropped)
+		bucket.mutedUntil = 0
+		bucket.dropped = 0
+		bucket.tokens = autoMuteWriter.opts.Burst
+		bucket.updatedAt = now
+	}
+
+	bucket.tokens += float64(now-bucket.updatedAt) / float64(time.Second) * autoMuteWriter.opts.Rate
+	if bucket.tokens > autoMuteWriter.opts.Burst {
+		bucket.tokens = autoMuteWriter.opts.Burst
+	}
+	bucket.updatedAt = now
+
+	if bucket.tokens < 1 {
+		bucket.mutedUntil = now + int64(autoMuteWriter.opts.Cooldown)
+		bucket.dropped = 1
+		return false, autoMuteWriter.notice(pkg, "Muted %q for %v after exceeding %v logs per second", pkg, autoMuteWriter.opts.Cooldown, autoMuteWriter.opts.Rate)
+	}
+
+	bucket.tokens--
+	return true, notice
+}
+
+func (autoMuteWriter *AutoMuteWriter) notice(pkg, format string, args ...interface{}) *Log {
+	return &Log{
+		Package: "logger",
+		Level:   "WARN",
+		Message: fmt.Sprintf(format, args...),
+		Time:    Now(),
+		Attrs:   &Attrs{"muted_package": pkg},
+		notice:  true,
+	}
+}
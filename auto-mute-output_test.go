@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAutoMuteWriter(t *testing.T) {
+	var buf bytes.Buffer
+	inner := Config{Format: FormatJSON, Levels: "*@error"}.standardWriter(&buf)
+	autoMuteWriter := NewAutoMuteWriter(inner, AutoMuteOptions{Rate: 1, Burst: 2, Cooldown: 50 * time.Millisecond})
+
+	for i := 0; i < 5; i++ {
+		autoMuteWriter.Write(&Log{Package: "db", Level: "ERROR", Message: "failed"})
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	autoMuteWriter.Write(&Log{Package: "db", Level: "ERROR", Message: "recovered"})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{"failed", "failed", `Muted \"db\"`, `Unmuted \"db\", 3 logs were dropped`, "recovered"}
+
+	if len(lines) != len(want) {
+		t.Fatalf("wrote %d lines, want %d:\n%s", len(lines), len(want), buf.String())
+	}
+
+	for i, line := range lines {
+		if !strings.Contains(line, want[i]) {
+			t.Errorf("line %d is %s, want it to contain %s", i, line, want[i])
+		}
+	}
+}
+
+func TestAutoMuteWriterBurst(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    AutoMuteOptions
+		allowed int
+	}{
+		{"DefaultsToRate", AutoMuteOptions{Rate: 3}, 3},
+		{"Explicit", AutoMuteOptions{Rate: 3, Burst: 5}, 5},
+		{"AtLeastOne", AutoMuteOptions{Rate: 0.5}, 1},
+		{"Negative", AutoMuteOptions{Rate: 0.5, Burst: -1}, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.opts.Cooldown = time.Hour
+			recorder := &recordWriter{}
+			autoMuteWriter := NewAutoMuteWriter(recorder, test.opts)
+
+			for i := 0; i < 10; i++ {
+				autoMuteWriter.Write(&Log{Package: "db", Level: "INFO", Message: "spam"})
+			}
+
+			allowed := 0
+			for _, log := range recorder.logs {
+				if log.Package == "db" {
+					allowed++
+				}
+			}
+
+			if allowed != test.allowed {
+				t.Errorf("allowed %d of 10 logs, want %d", allowed, test.allowed)
+			}
+		})
+	}
+}
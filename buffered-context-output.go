@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// BufferedContextWriter groups the logs of a request and writes them to an inner
+// writer together once the request ends. Logs are matched to requests by their
+// "request_id" attribute, see ContextWithRequestID. Logs of requests that
+// haven't begun are written right away.
+type BufferedContextWriter struct {
+	// OnlyOnError discards the logs of a committed request unless one of them is
+	// an ERROR.
+	OnlyOnError bool
+
+	inner    OutputWriter
+	mutex    sync.Mutex
+	requests map[string]*requestBuffer
+}
+
+type requestBuffer struct {
+	logs   []*Log
+	failed bool
+}
+
+// NewBufferedContextWriter returns a BufferedContextWriter writing to inner.
+func NewBufferedContextWriter(inner OutputWriter) *BufferedContextWriter {
+	return &BufferedContextWriter{
+		inner:    inner,
+		requests: map[string]*requestBuffer{},
+	}
+}
+
+func (bufferedWriter *BufferedContextWriter) Init() {
+	bufferedWriter.inner.Init()
+}
+
+func (bufferedWriter *BufferedContextWriter) Write(log *Log) {
+	if id := logRequestID(log); id != "" {
+		bufferedWriter.mutex.Lock()
+		buffer, ok := bufferedWriter.requests[id]
+		if ok {
+			buffer.logs = append(buffer.logs, log.Clone())
+			buffer.failed = buffer.failed || log.Level == "ERROR"
+		}
+		bufferedWriter.mutex.Unlock()
+
+		if ok {
+			return
+		}
+	}
+
+	bufferedWriter.inner.Write(log)
+}
+
+// Begin starts buffering the logs of the request carried by ctx.
+func (bufferedWriter *BufferedContextWriter) Begin(ctx context.Context) {
+	id := RequestIDFromContext(ctx)
+	if id == "" {
+		return
+	}
+
+	bufferedWriter.mutex.Lock()
+	defer bufferedWriter.mutex.Unlock()
+
+	if _, ok := bufferedWriter.requests[id]; !ok {
+		bufferedWriter.requests[id] = &requestBuffer{}
+	}
+}
+
+// Commit writes the buffered logs of the request carried by ctx, unless
+// OnlyOnError is set and none of them is an ERROR.
+func (bufferedWriter *BufferedContextWriter) Commit(ctx context.Context) {
+	buffer := bufferedWriter.end(ctx)
+	if buffer == nil || (bufferedWriter.OnlyOnError && !buffer.failed) {
+		return
+	}
+
+	for _, log := range buffer.logs {
+		bufferedWriter.inner.Write(log)
+	}
+}
+
+// Discard drops the buffered logs of the request carried by ctx.
+func (bufferedWriter *BufferedContextWriter) Discard(ctx context.Context) {
+	bufferedWriter.end(ctx)
+}
+
+func (bufferedWriter *BufferedContextWriter) end(ctx context.Context) *requestBuffer {
+	id := RequestIDFromContext(ctx)
+
+	bufferedWriter.mutex.Lock()
+	defer bufferedWriter.mutex.Unlock()
+
+	buffer := bufferedWriter.requests[id]
+	delete(bufferedWriter.requests, id)
+	return buffer
+}
+
+func logRequestID(log *Log) string {
+	if log.Attrs == nil {
+		return ""
+	}
+
+	id, ok := (*log.Attrs)[RequestIDKey]
+	if !ok || id == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%v", id)
+}
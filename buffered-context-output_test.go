@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBufferedContextWriter(t *testing.T) {
+	tests := []struct {
+		name        string
+		onlyOnError bool
+		begin       bool
+		levels      []string
+		end         func(*BufferedContextWriter, context.Context)
+		written     int
+	}{
+		{"Commit", false, true, []string{"INFO", "DEBUG"}, (*BufferedContextWriter).Commit, 2},
+		{"Discard", false, true, []string{"INFO", "ERROR"}, (*BufferedContextWriter).Discard, 0},
+		{"NotBegun", false, false, []string{"INFO", "DEBUG"}, (*BufferedContextWriter).Discard, 2},
+		{"OnlyOnErrorWithError", true, true, []string{"INFO", "ERROR"}, (*BufferedContextWriter).Commit, 2},
+		{"OnlyOnErrorWithoutError", true, true, []string{"INFO", "WARN"}, (*BufferedContextWriter).Commit, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := &recordWriter{}
+			bufferedWriter := NewBufferedContextWriter(recorder)
+			bufferedWriter.OnlyOnError = test.onlyOnError
+
+			ctx := ContextWithRequestID(context.Background(), "req-1")
+			if test.begin {
+				bufferedWriter.Begin(ctx)
+			}
+
+			for _, level := range test.levels {
+				bufferedWriter.Write(&Log{Level: level, Message: level, Attrs: &Attrs{RequestIDKey: "req-1"}})
+			}
+
+			// Logs of other requests aren't held up.
+			bufferedWriter.Write(&Log{Level: "INFO", Message: "other", Attrs: &Attrs{RequestIDKey: "req-2"}})
+			if len(recorder.logs) == 0 || recorder.logs[len(recorder.logs)-1].Message != "other" {
+				t.Fatalf("the log of another request wasn't written right away: %v", recorder.logs)
+			}
+
+			test.end(bufferedWriter, ctx)
+
+			var written []string
+			for _, log := range recorder.logs {
+				if log.Message != "other" {
+					written = append(written, log.Message)
+				}
+			}
+
+			if len(written) != test.written {
+				t.Fatalf("wrote %v, want %d logs", written, test.written)
+			}
+
+			for i, message := range written {
+				if message != test.levels[i] {
+					t.Errorf("log %d is %q, want %q", i, message, test.levels[i])
+				}
+			}
+
+			// The request is over: later logs are written right away.
+			before := len(recorder.logs)
+			bufferedWriter.Write(&Log{Level: "INFO", Message: "late", Attrs: &Attrs{RequestIDKey: "req-1"}})
+			if len(recorder.logs) != before+1 {
+				t.Error("a log of an ended request was buffered")
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !go1.18
+// +build !go1.18
+
+package logger
+
+// vcsAttrs returns no attributes, as VCS stamping requires Go 1.18.
+func vcsAttrs() Attrs {
+	return Attrs{}
+}
@@ -0,0 +1,39 @@
+package logger
+
+// BuildInfoWriter adds the VCS revision and commit time stamped into the binary by
+// the Go toolchain to every log, as the "vcs.revision" and "vcs.time" attributes.
+// They're omitted when the binary wasn't built with VCS stamping.
+type BuildInfoWriter struct {
+	inner OutputWriter
+	attrs Attrs
+}
+
+// WithBuildInfo wraps inner with build information attributes. The build
+// information is read once, here.
+func WithBuildInfo(inner OutputWriter) *BuildInfoWriter {
+	return &BuildInfoWriter{
+		inner: inner,
+		attrs: vcsAttrs(),
+	}
+}
+
+func (buildInfoWriter *BuildInfoWriter) Init() {
+	buildInfoWriter.inner.Init()
+}
+
+func (buildInfoWriter *BuildInfoWriter) Write(log *Log) {
+	if len(buildInfoWriter.attrs) == 0 {
+		buildInfoWriter.inner.Write(log)
+		return
+	}
+
+	// Copy the log rather than modifying it, as other writers receive it too.
+	withInfo := *log
+	attrs := mergeAttrs(nil, buildInfoWriter.attrs)
+	if log.Attrs != nil {
+		attrs = mergeAttrs(attrs, *log.Attrs)
+	}
+	withInfo.Attrs = &attrs
+
+	buildInfoWriter.inner.Write(&withInfo)
+}
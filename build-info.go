@@ -0,0 +1,27 @@
+//go:build go1.18
+// +build go1.18
+
+package logger
+
+import (
+	"runtime/debug"
+)
+
+// vcsAttrs returns the VCS stamping of the binary as attributes.
+func vcsAttrs() Attrs {
+	attrs := Attrs{}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return attrs
+	}
+
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision", "vcs.time":
+			attrs[setting.Key] = setting.Value
+		}
+	}
+
+	return attrs
+}
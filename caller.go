@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	goruntime "runtime"
+	"strings"
+)
+
+// CallerKey is the attribute holding the file:line of the call site of a log,
+// for loggers with ShowCaller set.
+const CallerKey = "caller"
+
+// packagePath is the import path of this package, whose frames are skipped when
+// looking for the call site.
+var packagePath = reflect.TypeOf(Logger{}).PkgPath()
+
+// callerFrame returns the first frame outside of this package, skipping skip more
+// frames, e.g. those of a wrapper library.
+func callerFrame(skip int) (goruntime.Frame, bool) {
+	pcs := make([]uintptr, 32)
+	frames := goruntime.CallersFrames(pcs[:goruntime.Callers(2, pcs)])
+
+	for {
+		frame, more := frames.Next()
+
+		if !strings.HasPrefix(frame.Function, packagePath+".") {
+			if skip == 0 {
+				return frame, true
+			}
+			skip--
+		}
+
+		if !more {
+			return goruntime.Frame{}, false
+		}
+	}
+}
+
+// caller formats the call site as the file's directory, name and line, e.g.
+// "server/handler.go:42".
+func caller(skip int) string {
+	frame, ok := callerFrame(skip)
+	if !ok {
+		return ""
+	}
+
+	dir, file := filepath.Split(frame.File)
+	return fmt.Sprintf("%s/%s:%d", filepath.Base(dir), file, frame.Line)
+}
+
+// sourcePackage returns the import path of the package of the call site, e.g.
+// "github.com/acme/api/server", read from the name of its function.
+func sourcePackage(skip int) string {
+	frame, ok := callerFrame(skip)
+	if !ok {
+		return ""
+	}
+
+	return functionPackage(frame.Function)
+}
+
+// functionPackage returns the package path of a fully qualified function name,
+// like "github.com/acme/api/server.(*Server).Handle".
+func functionPackage(function string) string {
+	slash := strings.LastIndexByte(function, '/') + 1
+	if dot := strings.IndexByte(function[slash:], '.'); dot >= 0 {
+		return function[:slash+dot]
+	}
+
+	return function
+}
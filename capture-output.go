@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+)
+
+// CaptureOutput runs f with the runtime's writers replaced by a JSON standard
+// output writing to an os.Pipe, then returns the logs parsed back from the pipe
+// along with the raw output, e.g. to test a program logging to stdout through the
+// same *os.File path. The packages and levels captured follow the LOG envvars.
+// The previous writers are restored once f returns.
+//
+// Loggers with their own Writer, and routed packages, aren't captured. A line
+// that isn't a JSON log is reported as an error, after the logs parsed so far.
+func CaptureOutput(f func()) ([]*Log, string, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, "", err
+	}
+	defer r.Close()
+
+	// Drain the pipe while f runs, so that it doesn't block once full.
+	type result struct {
+		output []byte
+		err    error
+	}
+	read := make(chan result, 1)
+	go func() {
+		output, err := ioutil.ReadAll(r)
+		read <- result{output, err}
+	}()
+
+	config := ConfigFromEnv()
+	config.Format = FormatJSON
+	writer := config.standardWriter(w)
+	writer.Init()
+
+	var previous []OutputWriter
+	runtime.setWriters(func(writers []OutputWriter) []OutputWriter {
+		previous = writers
+		return []OutputWriter{writer}
+	})
+
+	func() {
+		defer func() {
+			runtime.setWriters(func([]OutputWriter) []OutputWriter {
+				return previous
+			})
+			w.Close()
+		}()
+
+		f()
+	}()
+
+	res := <-read
+	if res.err != nil {
+		return nil, string(res.output), res.err
+	}
+
+	var logs []*Log
+	for _, line := range bytes.Split(res.output, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		log, err := parseJSONLog(line, writer.levelKey())
+		if err != nil {
+			return logs, string(res.output), err
+		}
+		logs = append(logs, log)
+	}
+
+	return logs, string(res.output), nil
+}
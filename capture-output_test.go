@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCaptureOutput(t *testing.T) {
+	unsetEnv(t, "LOG", "LOG_JSON", "LOG_LEVEL", "LOG_FORMAT")
+	os.Setenv("LOG", "db@info,http@error")
+	t.Cleanup(func() { os.Unsetenv("LOG") })
+
+	keepWriters(t)
+	current := &recordWriter{}
+	runtime.setWriters(func([]OutputWriter) []OutputWriter {
+		return []OutputWriter{current}
+	})
+
+	own, _ := recordLogger("db")
+
+	logs, output, err := CaptureOutput(func() {
+		New("db").Info("connected", Attrs{"pool": 2})
+		New("db").Debug("filtered")
+		New("http").Warn("filtered")
+		New("http").Error("failed")
+		own.Info("not captured")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		pkg     string
+		level   string
+		message string
+	}{
+		{"db", "INFO", "connected"},
+		{"http", "ERROR", "failed"},
+	}
+
+	if len(logs) != len(tests) {
+		t.Fatalf("captured %d logs, want %d:\n%s", len(logs), len(tests), output)
+	}
+
+	for i, test := range tests {
+		if logs[i].Package != test.pkg || logs[i].Level != test.level || logs[i].Message != test.message {
+			t.Errorf("log %d is %+v, want %s %s %q", i, logs[i], test.pkg, test.level, test.message)
+		}
+	}
+
+	if logs[0].Attrs == nil || (*logs[0].Attrs)["pool"] != float64(2) {
+		t.Errorf("the attributes weren't parsed back: %v", logs[0].Attrs)
+	}
+
+	if lines := strings.Count(output, "\n"); lines != 2 {
+		t.Errorf("the raw output has %d lines, want 2:\n%s", lines, output)
+	}
+
+	New("db").Info("after capture")
+	if len(current.logs) != 1 {
+		t.Errorf("the previous writers got %d logs, want only the one after the capture", len(current.logs))
+	}
+}
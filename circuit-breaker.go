@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Settings of the circuit breaker of the runtime, which stops writing logs for a
+// while once its writers keep failing, e.g. on a full disk or a network outage,
+// rather than slowing the program down with writes bound to fail. Failures are
+// only seen for writers implementing TryWriter, or that panic. They're to be set
+// before logging.
+var (
+	// BreakerThreshold is the number of consecutive logs that every writer failed
+	// to write after which the breaker opens. Zero disables the breaker.
+	BreakerThreshold = 5
+
+	// BreakerCooldown is how long the breaker stays open before writes are tried
+	// again.
+	BreakerCooldown = 30 * time.Second
+
+	// BreakerBuffer is the number of the latest logs kept while the breaker is
+	// open, written once the writers recover.
+	BreakerBuffer = 100
+)
+
+// circuitBreaker guards the writes of the runtime. openUntil is zero while it's
+// closed, which is checked without locking.
+type circuitBreaker struct {
+	failures  int64
+	openUntil int64
+
+	mutex   sync.Mutex
+	pending []*Log
+	dropped int
+}
+
+var breaker circuitBreaker
+
+// LoggingHealthy reports whether the runtime's writers are being written to, i.e.
+// the circuit breaker isn't open.
+func LoggingHealthy() bool {
+	return atomic.LoadInt64(&breaker.openUntil) == 0
+}
+
+// hold keeps the log for later and returns true while the breaker is open. Once
+// the cooldown is over, logs are let through again to try the writers.
+func (breaker *circuitBreaker) hold(log *Log) bool {
+	until := atomic.LoadInt64(&breaker.openUntil)
+	if until == 0 || Now() >= until {
+		return false
+	}
+
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	if BreakerBuffer <= 0 {
+		breaker.dropped++
+		return true
+	}
+
+	if len(breaker.pending) >= BreakerBuffer {
+		breaker.pending = breaker.pending[1:]
+		breaker.dropped++
+	}
+	breaker.pending = append(breaker.pending, log.Clone())
+
+	return true
+}
+
+// record counts a log that every writer failed to write, or resets the count and
+// closes the breaker when a log got written. It returns the logs held while the
+// breaker was open, and how many were dropped, once it closes.
+func (breaker *circuitBreaker) record(failed bool) (pending []*Log, dropped int) {
+	if failed {
+		threshold := int64(BreakerThreshold)
+		if threshold > 0 && atomic.AddInt64(&breaker.failures, 1) >= threshold {
+			atomic.StoreInt64(&breaker.openUntil, Now()+int64(BreakerCooldown))
+			// Once tried again, a single failure opens the breaker again.
+			atomic.StoreInt64(&breaker.failures, threshold-1)
+		}
+		return nil, 0
+	}
+
+	if atomic.LoadInt64(&breaker.failures) != 0 {
+		atomic.StoreInt64(&breaker.failures, 0)
+	}
+
+	if atomic.LoadInt64(&breaker.openUntil) == 0 {
+		return nil, 0
+	}
+
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	// Another log may have closed it meanwhile.
+	if atomic.LoadInt64(&breaker.openUntil) == 0 {
+		return nil, 0
+	}
+	atomic.StoreInt64(&breaker.openUntil, 0)
+
+	pending, dropped = breaker.pending, breaker.dropped
+	breaker.pending, breaker.dropped = nil, 0
+
+	return pending, dropped
+}
@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	threshold, cooldown, buffer := BreakerThreshold, BreakerCooldown, BreakerBuffer
+	BreakerThreshold, BreakerCooldown, BreakerBuffer = 2, 50*time.Millisecond, 2
+	t.Cleanup(func() {
+		BreakerThreshold, BreakerCooldown, BreakerBuffer = threshold, cooldown, buffer
+		breaker = circuitBreaker{}
+	})
+
+	keepWriters(t)
+	writer := &failWriter{failing: true}
+	runtime.setWriters(func([]OutputWriter) []OutputWriter {
+		return []OutputWriter{writer}
+	})
+
+	steps := []struct {
+		message string
+		failing bool
+		sleep   time.Duration
+		writes  int
+		healthy bool
+	}{
+		{"first", true, 0, 1, true},
+		{"second", true, 0, 2, false},
+		{"held", true, 0, 2, false},
+		{"kept", true, 0, 2, false},
+		{"kept too", true, 0, 2, false},
+		{"recovered", false, 60 * time.Millisecond, 6, true},
+	}
+
+	for _, step := range steps {
+		time.Sleep(step.sleep)
+		writer.failing = step.failing
+		runtime.Log(&Log{Package: "db", Level: "INFO", Message: step.message})
+
+		if writer.writes != step.writes {
+			t.Errorf("after %q: %d writes, want %d", step.message, writer.writes, step.writes)
+		}
+
+		if healthy := LoggingHealthy(); healthy != step.healthy {
+			t.Errorf("after %q: LoggingHealthy() = %v, want %v", step.message, healthy, step.healthy)
+		}
+	}
+
+	want := []string{"recovered", "kept", "kept too", "Logs dropped while the writers were failing"}
+	if !reflect.DeepEqual(writer.messages, want) {
+		t.Errorf("wrote %q, want %q", writer.messages, want)
+	}
+}
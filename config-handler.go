@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// ConfigHandler returns an HTTP handler for viewing and changing the package
+// settings of the standard writers at runtime. GET returns the effective settings
+// as JSON; PUT or POST with a LOG spec as body (e.g. "*@error,database@timer")
+// applies it to every standard writer, then returns the new settings.
+func ConfigHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, 64*1024))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			Configure(strings.TrimSpace(string(body)))
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(effectiveConfig())
+	})
+}
+
+// Configure applies the LOG spec to every standard writer of the runtime.
+func Configure(spec string) {
+	for _, writer := range runtime.writers() {
+		if standardWriter := asStandardWriter(writer); standardWriter != nil {
+			standardWriter.Configure(spec)
+		}
+	}
+}
+
+type writerConfig struct {
+	Log      string            `json:"log"`
+	Packages map[string]string `json:"packages"`
+}
+
+func effectiveConfig() []writerConfig {
+	configs := []writerConfig{}
+
+	for _, writer := range runtime.writers() {
+		standardWriter := asStandardWriter(writer)
+		if standardWriter == nil {
+			continue
+		}
+
+		settingsMutex.RLock()
+		config := writerConfig{
+			Log:      formatPackageSettings(standardWriter.Settings),
+			Packages: map[string]string{},
+		}
+		for name, settings := range standardWriter.Settings {
+			config.Packages[name] = settings.String()
+		}
+		settingsMutex.RUnlock()
+
+		configs = append(configs, config)
+	}
+
+	return configs
+}
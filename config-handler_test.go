@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestConfigHandler(t *testing.T) {
+	unsetEnv(t, "LOG_LEVEL")
+
+	tests := []struct {
+		method  string
+		body    string
+		status  int
+		log     string
+		enabled bool
+	}{
+		{http.MethodGet, "", http.StatusOK, "*@error", false},
+		{http.MethodPut, "*@error,db@debug\n", http.StatusOK, "*@error,db@debug", true},
+		{http.MethodPost, "*@error", http.StatusOK, "*@error", false},
+		{http.MethodDelete, "", http.StatusMethodNotAllowed, "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.method, func(t *testing.T) {
+			writer := runtimeWriter(t, "*@error")
+
+			recorder := httptest.NewRecorder()
+			ConfigHandler().ServeHTTP(recorder, httptest.NewRequest(test.method, "/log", strings.NewReader(test.body)))
+
+			if recorder.Code != test.status {
+				t.Fatalf("status = %d, want %d", recorder.Code, test.status)
+			}
+
+			if got := writer.IsEnabled("db", "DEBUG"); got != test.enabled {
+				t.Errorf("db DEBUG enabled = %v, want %v", got, test.enabled)
+			}
+
+			if test.status != http.StatusOK {
+				if allow := recorder.Header().Get("Allow"); allow != "GET, PUT, POST" {
+					t.Errorf("Allow = %q, want the supported methods", allow)
+				}
+				return
+			}
+
+			var configs []writerConfig
+			if err := json.Unmarshal(recorder.Body.Bytes(), &configs); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(configs) != 1 || configs[0].Log != test.log {
+				t.Errorf("got %+v, want the settings %q", configs, test.log)
+			}
+		})
+	}
+}
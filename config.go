@@ -0,0 +1,250 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ColorMode selects whether pretty output is colored.
+type ColorMode int
+
+const (
+	// ColorsAuto colors the output of targets that are terminals.
+	ColorsAuto ColorMode = iota
+	ColorsAlways
+	ColorsNever
+)
+
+// Config gathers the settings of an output, for setting it up from code rather
+// than envvars. The zero value writes to stderr like the default output, without
+// reading the envvars.
+type Config struct {
+	// Targets receive every log. Defaults to os.Stderr. Each target gets a
+	// standard output of its own, with the same settings.
+	Targets []io.Writer
+
+	// Format is one of FormatPretty, FormatJSON, FormatLogfmt or FormatECS. When
+	// empty, targets that are terminals get pretty output and the others JSON.
+	Format string
+
+	// Colors selects whether pretty output is colored. Defaults to ColorsAuto.
+	Colors ColorMode
+
+	// TimeFormat is the layout of pretty timestamps. Defaults to DefaultTimeFormat.
+	TimeFormat string
+
+	// LevelKey names the level field of JSON and logfmt output. Defaults to
+	// "level".
+	LevelKey string
+
+	// Levels selects the packages and levels shown, in the syntax of the LOG
+	// envvar, e.g. "*@error,database@timer". Defaults to "*".
+	Levels string
+
+	// Packages maps package names to levels, as an alternative to Levels, which
+	// it takes precedence over.
+	Packages map[string]string
+
+	// DefaultLevel is the level of the packages selected without one, as the
+	// LOG_LEVEL envvar. Defaults to info.
+	DefaultLevel string
+
+	// Attrs are added to every log, under the log's own attributes.
+	Attrs Attrs
+
+	// Buffering and BufferSize set how the targets are buffered. Defaults to
+	// BufferNone. See StandardWriter.SetBuffering.
+	Buffering  BufferMode
+	BufferSize int
+
+	// SampleRate, between 0 and 1, is the fraction of logs written. Logs already
+	// sampled by their logger (see Sampled) aren't sampled again. Zero writes
+	// every log.
+	SampleRate float64
+}
+
+// ConfigFromEnv returns the configuration given by the LOG, LOG_JSON, LOG_LEVEL
+// and LOG_FORMAT envvars, as used by NewStandardOutput. A malformed LOG_JSON is
+// reported once on stderr and LOG is used instead.
+func ConfigFromEnv() Config {
+	config := Config{
+		Format:       strings.ToLower(strings.TrimSpace(os.Getenv("LOG_FORMAT"))),
+		Levels:       os.Getenv("LOG"),
+		DefaultLevel: os.Getenv("LOG_LEVEL"),
+	}
+
+	if spec := os.Getenv("LOG_JSON"); spec != "" {
+		if err := json.Unmarshal([]byte(spec), &config.Packages); err != nil {
+			config.Packages = nil
+			warnJSONSettings.Do(func() {
+				fmt.Fprintf(os.Stderr, "logger: ignoring malformed LOG_JSON, using LOG instead: %v\n", err)
+			})
+		}
+	}
+
+	return config
+}
+
+var warnJSONSettings sync.Once
+
+// NewOutput returns a writer set up by config, to be installed with Hook or a
+// Pipeline. It implements Flusher, for buffered targets.
+func NewOutput(config Config) OutputWriter {
+	targets := config.Targets
+	if len(targets) == 0 {
+		targets = []io.Writer{os.Stderr}
+	}
+
+	writers := make([]OutputWriter, len(targets))
+	for i, target := range targets {
+		writers[i] = config.standardWriter(target)
+	}
+
+	writer := writers[0]
+	if len(writers) > 1 {
+		writer = NewMultiWriter(writers, MultiOptions{})
+	}
+
+	if len(config.Attrs) > 0 {
+		writer = &attrsWriter{inner: writer, attrs: config.Attrs}
+	}
+
+	if config.SampleRate > 0 && config.SampleRate < 1 {
+		writer = &sampleWriter{inner: writer, rate: config.SampleRate}
+	}
+
+	return writer
+}
+
+// standardWriter returns a standard output writing to w with the settings of the
+// configuration.
+func (config Config) standardWriter(w io.Writer) StandardWriter {
+	tty := isTerminal(w)
+
+	var writer = StandardWriter{
+		ColorsEnabled: config.Colors == ColorsAlways || config.Colors == ColorsAuto && tty,
+		Target:        w,
+		Separator:     " ",
+		TimeFormat:    config.TimeFormat,
+		LevelKey:      config.LevelKey,
+		OutputFormat:  config.Format,
+		MaxBinaryAttr: DefaultMaxBinaryAttr,
+		Floor:         &OutputSettings{Debug: true, Info: true, Timer: true, Warn: true, Error: true},
+	}
+
+	if writer.TimeFormat == "" {
+		writer.TimeFormat = DefaultTimeFormat
+	}
+
+	if writer.OutputFormat == "" {
+		writer.OutputFormat = FormatJSON
+		if tty {
+			writer.OutputFormat = FormatPretty
+		}
+	}
+
+	writer.Settings = config.settings()
+	writer.SetBuffering(config.Buffering, config.BufferSize)
+
+	return writer
+}
+
+func (config Config) settings() map[string]*OutputSettings {
+	defaultOutputSettings := parseVerbosityLevel(config.DefaultLevel)
+
+	if config.Packages != nil {
+		all := map[string]*OutputSettings{}
+		for name, level := range config.Packages {
+			if strings.TrimSpace(level) == "" {
+				all[name] = defaultOutputSettings
+			} else {
+				all[name] = parseVerbosityLevel(level)
+			}
+		}
+		return all
+	}
+
+	spec := config.Levels
+	if spec == "" {
+		spec = "*"
+	}
+
+	return parsePackageSettings(spec, defaultOutputSettings)
+}
+
+// attrsWriter adds static attributes to every log.
+type attrsWriter struct {
+	inner OutputWriter
+	attrs Attrs
+}
+
+func (attrsWriter *attrsWriter) Init() {
+	attrsWriter.inner.Init()
+}
+
+// Flush flushes the inner writer, if it buffers its output.
+func (attrsWriter *attrsWriter) Flush() error {
+	return flushWriter(attrsWriter.inner)
+}
+
+func (attrsWriter *attrsWriter) Write(log *Log) {
+	// Copy the log rather than modifying it, as other writers receive it too.
+	withAttrs := *log
+	attrs := mergeAttrs(nil, attrsWriter.attrs)
+	if log.Attrs != nil {
+		attrs = mergeAttrs(attrs, *log.Attrs)
+	}
+	withAttrs.Attrs = &attrs
+
+	attrsWriter.inner.Write(&withAttrs)
+}
+
+// sampleWriter writes a random fraction of the logs, marking them as sampled.
+type sampleWriter struct {
+	inner OutputWriter
+	rate  float64
+}
+
+func (sampleWriter *sampleWriter) Init() {
+	sampleWriter.inner.Init()
+}
+
+// Flush flushes the inner writer, if it buffers its output.
+func (sampleWriter *sampleWriter) Flush() error {
+	return flushWriter(sampleWriter.inner)
+}
+
+func (sampleWriter *sampleWriter) Write(log *Log) {
+	if log.Attrs != nil {
+		if sampled, _ := (*log.Attrs)[SampledKey].(bool); sampled {
+			sampleWriter.inner.Write(log)
+			return
+		}
+	}
+
+	if rand.Float64() >= sampleWriter.rate {
+		return
+	}
+
+	sampled := *log
+	attrs := Attrs{SampledKey: true}
+	if log.Attrs != nil {
+		attrs = mergeAttrs(attrs, *log.Attrs)
+	}
+	sampled.Attrs = &attrs
+
+	sampleWriter.inner.Write(&sampled)
+}
+
+func flushWriter(writer OutputWriter) error {
+	if flusher, ok := writer.(Flusher); ok {
+		return flusher.Flush()
+	}
+
+	return nil
+}
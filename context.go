@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"context"
+)
+
+// Attributes under which the IDs carried by a context are logged.
+const (
+	RequestIDKey = "request_id"
+	UserIDKey    = "user_id"
+	TraceIDKey   = "trace_id"
+)
+
+type contextKey int
+
+const (
+	requestIDContextKey contextKey = iota
+	loggerContextKey
+	userIDContextKey
+	traceIDContextKey
+)
+
+// ContextWithRequestID returns a copy of ctx carrying the given request ID.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, if any.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey).(string)
+	return id
+}
+
+// ContextWithUserID returns a copy of ctx carrying the given user ID.
+func ContextWithUserID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, userIDContextKey, id)
+}
+
+// UserIDFromContext returns the user ID carried by ctx, if any.
+func UserIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(userIDContextKey).(string)
+	return id
+}
+
+// ContextWithTraceID returns a copy of ctx carrying the given trace ID.
+func ContextWithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceIDContextKey, id)
+}
+
+// TraceIDFromContext returns the trace ID carried by ctx, if any.
+func TraceIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(traceIDContextKey).(string)
+	return id
+}
+
+// ContextAttrs returns the request, user and trace IDs carried by ctx as
+// attributes, as attached by the Ctx logging methods. It's nil when ctx carries
+// none of them.
+func ContextAttrs(ctx context.Context) Attrs {
+	var attrs Attrs
+
+	for key, id := range map[string]string{
+		RequestIDKey: RequestIDFromContext(ctx),
+		UserIDKey:    UserIDFromContext(ctx),
+		TraceIDKey:   TraceIDFromContext(ctx),
+	} {
+		if id == "" {
+			continue
+		}
+
+		if attrs == nil {
+			attrs = Attrs{}
+		}
+		attrs[key] = id
+	}
+
+	return attrs
+}
+
+// ContextWithLogger returns a copy of ctx carrying the given logger.
+func ContextWithLogger(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey, logger)
+}
+
+// LoggerFromContext returns the logger carried by ctx, or nil.
+func LoggerFromContext(ctx context.Context) *Logger {
+	logger, _ := ctx.Value(loggerContextKey).(*Logger)
+	return logger
+}
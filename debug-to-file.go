@@ -0,0 +1,54 @@
+package logger
+
+import "sync"
+
+// DebugFileMaxSize is the size at which the file of DebugToFile is rotated.
+const DebugFileMaxSize = 10 * 1024 * 1024
+
+var debugFile struct {
+	sync.Mutex
+	writer *FileWriter
+}
+
+// DebugToFile writes every log of every package, debug logs included, to the file
+// at path, next to the current writers which keep their settings. This gets the
+// full detail on disk while the console shows the usual summary. The file is
+// rotated at DebugFileMaxSize. Call StopDebugToFile to close it.
+func DebugToFile(path string) error {
+	writer, err := NewFileWriter(path, FileOptions{MaxSize: DebugFileMaxSize})
+	if err != nil {
+		return err
+	}
+
+	writer.Formatter.Configure("*@debug")
+
+	debugFile.Lock()
+	defer debugFile.Unlock()
+
+	if debugFile.writer != nil {
+		Unhook(debugFile.writer)
+		debugFile.writer.Close()
+	}
+
+	debugFile.writer = writer
+	Hook(writer)
+
+	return nil
+}
+
+// StopDebugToFile removes the writer installed by DebugToFile and closes its
+// file.
+func StopDebugToFile() error {
+	debugFile.Lock()
+	defer debugFile.Unlock()
+
+	if debugFile.writer == nil {
+		return nil
+	}
+
+	Unhook(debugFile.writer)
+	err := debugFile.writer.Close()
+	debugFile.writer = nil
+
+	return err
+}
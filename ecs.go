@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ECSVersion is the Elastic Common Schema version of the ECS output.
+const ECSVersion = "1.6.0"
+
+// ecsFields maps the fields of the JSON output to their ECS names. Fields that
+// aren't listed are dropped.
+var ecsFields = map[string]string{
+	"time":         "@timestamp",
+	"level":        "log.level",
+	"package":      "log.logger",
+	"msg":          "message",
+	"code":         "error.code",
+	"attrs":        "attrs",
+	"elapsed_nano": "event.duration",
+	"seq":          "event.sequence",
+	"started_at":   "event.start",
+	"ended_at":     "event.end",
+}
+
+// ECSFormat renders the log as JSON following the Elastic Common Schema, so that
+// it can be ingested by Elasticsearch without reshaping.
+func (standardWriter *StandardWriter) ECSFormat(log *Log) string {
+	fields := map[string]interface{}{
+		"ecs.version": ECSVersion,
+	}
+
+	for key, val := range standardWriter.jsonFields(log) {
+		if name, ok := ecsFields[key]; ok {
+			fields[name] = val
+		}
+	}
+
+	fields["@timestamp"] = time.Unix(0, log.Time).UTC().Format(time.RFC3339Nano)
+	fields["log.level"] = strings.ToLower(log.Level)
+
+	if log.Level != "TIMER" {
+		delete(fields, "event.duration")
+	}
+
+	str, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Sprintf(`{ "logger-error": "%v" }`, err)
+	}
+
+	return string(str)
+}
+
+// jsonFields returns the fields of the JSON output keyed by their JSON names.
+func (standardWriter *StandardWriter) jsonFields(log *Log) map[string]interface{} {
+	fields := map[string]interface{}{
+		"package":      log.Package,
+		"level":        log.Level,
+		"msg":          log.Message,
+		"time":         log.Time,
+		"elapsed":      log.Elapsed,
+		"elapsed_nano": log.ElapsedNano,
+		"seq":          log.Seq,
+	}
+
+	if log.Code != "" {
+		fields["code"] = log.Code
+	}
+
+	if log.StartedAt != nil {
+		fields["started_at"] = log.StartedAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	if log.EndedAt != nil {
+		fields["ended_at"] = log.EndedAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	if attrs := standardWriter.jsonAttrs(log.Attrs); attrs != nil {
+		fields["attrs"] = attrs
+	}
+
+	return fields
+}
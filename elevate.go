@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"reflect"
+	"sync"
+)
+
+// elevations tracks the packages whose settings are temporarily overridden by
+// Elevate, per writer settings map. Guarded by settingsMutex.
+var (
+	elevations      = map[elevationKey]*elevation{}
+	elevationNextID uint64
+)
+
+type elevationKey struct {
+	settings uintptr
+	pkg      string
+}
+
+type elevation struct {
+	base    *OutputSettings
+	hadBase bool
+	stack   []elevated
+}
+
+type elevated struct {
+	id       uint64
+	settings *OutputSettings
+}
+
+// Elevate temporarily sets the level of a package on every standard writer, e.g.
+// to debug a flaky operation, and returns a function undoing it:
+//
+//	restore := logger.Elevate("db", "debug")
+//	defer restore()
+//
+// Elevations can be nested and restored in any order; the package gets back the
+// settings it had before the first one once all of them are restored. Settings
+// changed meanwhile, e.g. by Configure or SetLevel, are kept.
+func Elevate(pkg, level string) (restore func()) {
+	settings := parseVerbosityLevel(level)
+	writers := runtime.writers()
+
+	settingsMutex.Lock()
+	defer settingsMutex.Unlock()
+
+	elevationNextID++
+	id := elevationNextID
+
+	var maps []map[string]*OutputSettings
+
+	for _, writer := range writers {
+		standardWriter := asStandardWriter(writer)
+		if standardWriter == nil || standardWriter.Settings == nil {
+			continue
+		}
+
+		key := elevationKey{reflect.ValueOf(standardWriter.Settings).Pointer(), pkg}
+		e, ok := elevations[key]
+		if !ok {
+			base, hadBase := standardWriter.Settings[pkg]
+			e = &elevation{base: base, hadBase: hadBase}
+			elevations[key] = e
+		}
+
+		e.stack = append(e.stack, elevated{id, settings})
+		standardWriter.Settings[pkg] = settings
+		maps = append(maps, standardWriter.Settings)
+	}
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			settingsMutex.Lock()
+			defer settingsMutex.Unlock()
+
+			for _, m := range maps {
+				restoreElevation(m, pkg, id)
+			}
+		})
+	}
+}
+
+func restoreElevation(settings map[string]*OutputSettings, pkg string, id uint64) {
+	key := elevationKey{reflect.ValueOf(settings).Pointer(), pkg}
+	e, ok := elevations[key]
+	if !ok {
+		return
+	}
+
+	// The package was configured again since it was elevated, so the pending
+	// elevations no longer own its entry.
+	if current, ok := settings[pkg]; !ok || current != e.stack[len(e.stack)-1].settings {
+		delete(elevations, key)
+		return
+	}
+
+	for i, entry := range e.stack {
+		if entry.id == id {
+			e.stack = append(e.stack[:i], e.stack[i+1:]...)
+			break
+		}
+	}
+
+	if len(e.stack) > 0 {
+		settings[pkg] = e.stack[len(e.stack)-1].settings
+		return
+	}
+
+	delete(elevations, key)
+
+	if e.hadBase {
+		settings[pkg] = e.base
+	} else {
+		delete(settings, pkg)
+	}
+}
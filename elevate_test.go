@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+)
+
+// runtimeWriter makes a standard writer configured with spec the only writer of
+// the runtime until the test ends.
+func runtimeWriter(t *testing.T, spec string) *StandardWriter {
+	var buf bytes.Buffer
+	writer := Config{Levels: spec}.standardWriter(&buf)
+
+	keepWriters(t)
+	runtime.setWriters(func([]OutputWriter) []OutputWriter {
+		return []OutputWriter{writer}
+	})
+
+	return &writer
+}
+
+func TestElevate(t *testing.T) {
+	writer := runtimeWriter(t, "*@warn")
+
+	outer := Elevate("db", "info")
+	inner := Elevate("db", "debug")
+
+	if !writer.IsEnabled("db", "DEBUG") {
+		t.Fatal("db DEBUG isn't enabled while elevated")
+	}
+
+	outer()
+
+	if !writer.IsEnabled("db", "DEBUG") {
+		t.Error("restoring the outer elevation first undid the inner one")
+	}
+
+	inner()
+
+	if writer.IsEnabled("db", "INFO") {
+		t.Error("db INFO is still enabled once every elevation is restored")
+	}
+
+	if _, ok := writer.Settings["db"]; ok {
+		t.Error("restoring left a db entry that wasn't there before")
+	}
+}
+
+func TestElevateThenConfigure(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(*StandardWriter)
+		pkg       string
+		check     string
+		debug     bool
+		info      bool
+	}{
+		{"Configure", func(writer *StandardWriter) { writer.Configure("db@info") }, "db", "db", false, true},
+		{"ConfigureWithout", func(writer *StandardWriter) { writer.Configure("*@error") }, "db", "db", false, false},
+		{"SetLevel", func(writer *StandardWriter) { writer.SetLevel(parseVerbosityLevel("info")) }, "*", "http", false, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			writer := runtimeWriter(t, "db@error,*@error")
+
+			restore := Elevate(test.pkg, "debug")
+			test.configure(writer)
+			restore()
+
+			if got := writer.IsEnabled(test.check, "DEBUG"); got != test.debug {
+				t.Errorf("%s DEBUG enabled = %v, want %v", test.check, got, test.debug)
+			}
+
+			if got := writer.IsEnabled(test.check, "INFO"); got != test.info {
+				t.Errorf("%s INFO enabled = %v, want %v", test.check, got, test.info)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ResolveEnv replaces the ${NAME} references in a writer setting with the value
+// of the NAME envvar, so that secrets such as auth tokens stay out of the code.
+// It fails naming the first variable that isn't set.
+func ResolveEnv(value string) (string, error) {
+	var b strings.Builder
+
+	for {
+		start := strings.Index(value, "${")
+		if start < 0 {
+			break
+		}
+
+		end := strings.IndexByte(value[start:], '}')
+		if end < 0 {
+			break
+		}
+
+		name := value[start+2 : start+end]
+		resolved, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("logger: environment variable %s is not set", name)
+		}
+
+		b.WriteString(value[:start])
+		b.WriteString(resolved)
+		value = value[start+end+1:]
+	}
+
+	b.WriteString(value)
+	return b.String(), nil
+}
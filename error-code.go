@@ -0,0 +1,53 @@
+package logger
+
+// ErrorCodeKey is the attribute holding the code of logs made with ErrorCode.
+const ErrorCodeKey = "error_code"
+
+// ValidateErrorCodes makes ErrorCode check that codes are letters followed by
+// digits, like "E1023". Invalid codes are logged under BadKey instead, so that
+// they don't pollute aggregations by code.
+var ValidateErrorCodes = false
+
+// ErrorCode logs an error with a stable code, set as the "code" field of JSON
+// output and the "error_code" attribute, so that dashboards can aggregate errors
+// by code rather than by message. Pretty output shows it in brackets before the
+// message.
+func (logger *Logger) ErrorCode(code, msg string, v ...interface{}) {
+	message, attrs := formatArgs(msg, v)
+
+	if ValidateErrorCodes && !validErrorCode(code) {
+		bad := Attrs{BadKey: code}
+		if attrs != nil {
+			bad = mergeAttrs(bad, *attrs)
+		}
+		logger.write("ERROR", message, &bad)
+		return
+	}
+
+	withCode := Attrs{ErrorCodeKey: code}
+	if attrs != nil {
+		withCode = mergeAttrs(withCode, *attrs)
+	}
+	logger.writeCode("ERROR", code, message, &withCode)
+}
+
+// validErrorCode reports whether code is one or more letters followed by one or
+// more digits.
+func validErrorCode(code string) bool {
+	i := 0
+	for i < len(code) && (code[i] >= 'A' && code[i] <= 'Z' || code[i] >= 'a' && code[i] <= 'z') {
+		i++
+	}
+
+	if i == 0 || i == len(code) {
+		return false
+	}
+
+	for ; i < len(code); i++ {
+		if code[i] < '0' || code[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+const errorRateBuckets = 10
+
+// ErrorRateOptions configures an ErrorRateWriter.
+type ErrorRateOptions struct {
+	// Window is the sliding window over which ERROR logs are counted. Defaults to
+	// one minute.
+	Window time.Duration
+
+	// Threshold is the rate, in errors per second over the window, above which
+	// OnAlert is called.
+	Threshold float64
+
+	// OnAlert receives the current error rate. It's called at most once per window.
+	OnAlert func(rate float64)
+}
+
+// ErrorRateWriter forwards every log to an inner writer while counting ERROR logs
+// over a sliding window, calling an alert callback when the error rate spikes.
+type ErrorRateWriter struct {
+	inner   OutputWriter
+	opts    ErrorRateOptions
+	bucket  int64
+	mutex   sync.Mutex
+	counts  [errorRateBuckets]int64
+	stamps  [errorRateBuckets]int64
+	alerted int64
+}
+
+// NewErrorRateWriter wraps inner with error rate alerting.
+func NewErrorRateWriter(inner OutputWriter, opts ErrorRateOptions) *ErrorRateWriter {
+	if opts.Window <= 0 {
+		opts.Window = time.Minute
+	}
+
+	bucket := int64(opts.Window) / errorRateBuckets
+	if bucket < 1 {
+		bucket = 1
+	}
+
+	return &ErrorRateWriter{
+		inner:  inner,
+		opts:   opts,
+		bucket: bucket,
+	}
+}
+
+func (errorRateWriter *ErrorRateWriter) Init() {
+	errorRateWriter.inner.Init()
+}
+
+func (errorRateWriter *ErrorRateWriter) Write(log *Log) {
+	errorRateWriter.inner.Write(log)
+
+	if log.Level != "ERROR" {
+		return
+	}
+
+	if rate, alert := errorRateWriter.record(Now()); alert {
+		errorRateWriter.opts.OnAlert(rate)
+	}
+}
+
+// Rate returns the current rate of ERROR logs per second over the window.
+func (errorRateWriter *ErrorRateWriter) Rate() float64 {
+	errorRateWriter.mutex.Lock()
+	defer errorRateWriter.mutex.Unlock()
+
+	return errorRateWriter.rate(Now() / errorRateWriter.bucket)
+}
+
+// record counts an error at the given time and returns the updated rate, and
+// whether to alert about it.
+func (errorRateWriter *ErrorRateWriter) record(now int64) (float64, bool) {
+	errorRateWriter.mutex.Lock()
+	defer errorRateWriter.mutex.Unlock()
+
+	index := now / errorRateWriter.bucket
+	slot := index % errorRateBuckets
+
+	if errorRateWriter.stamps[slot] != index {
+		errorRateWriter.stamps[slot] = index
+		errorRateWriter.counts[slot] = 0
+	}
+
+	errorRateWriter.counts[slot]++
+
+	rate := errorRateWriter.rate(index)
+	if rate <= errorRateWriter.opts.Threshold || errorRateWriter.opts.OnAlert == nil {
+		return rate, false
+	}
+
+	last := errorRateWriter.alerted
+	if last != 0 && now-last < int64(errorRateWriter.opts.Window) {
+		return rate, false
+	}
+
+	errorRateWriter.alerted = now
+	return rate, true
+}
+
+// rate returns the rate over the window ending in the bucket index. The caller
+// must hold the mutex.
+func (errorRateWriter *ErrorRateWriter) rate(index int64) float64 {
+	var total int64
+	for slot := range errorRateWriter.counts {
+		if index-errorRateWriter.stamps[slot] < errorRateBuckets {
+			total += errorRateWriter.counts[slot]
+		}
+	}
+
+	return float64(total) / errorRateWriter.opts.Window.Seconds()
+}
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestErrorRateWriterAlerts(t *testing.T) {
+	tests := []struct {
+		name      string
+		errors    int
+		infos     int
+		threshold float64
+		alerts    int
+	}{
+		{"BelowThreshold", 5, 0, 10, 0},
+		{"AtThreshold", 10, 0, 10, 0},
+		{"AboveThreshold", 11, 0, 10, 1},
+		{"OncePerWindow", 100, 0, 10, 1},
+		{"OnlyErrors", 5, 100, 10, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var rates []float64
+			recorder := &recordWriter{}
+			errorRateWriter := NewErrorRateWriter(recorder, ErrorRateOptions{
+				Window:    time.Second,
+				Threshold: test.threshold,
+				OnAlert:   func(rate float64) { rates = append(rates, rate) },
+			})
+
+			for i := 0; i < test.infos; i++ {
+				errorRateWriter.Write(&Log{Level: "INFO"})
+			}
+			for i := 0; i < test.errors; i++ {
+				errorRateWriter.Write(&Log{Level: "ERROR"})
+			}
+
+			if len(rates) != test.alerts {
+				t.Errorf("alerted %d times (%v), want %d", len(rates), rates, test.alerts)
+			}
+
+			if len(recorder.logs) != test.errors+test.infos {
+				t.Errorf("forwarded %d logs, want %d", len(recorder.logs), test.errors+test.infos)
+			}
+		})
+	}
+}
+
+func TestErrorRateWriterConcurrently(t *testing.T) {
+	errorRateWriter := NewErrorRateWriter(NullWriter{}, ErrorRateOptions{Window: time.Hour})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				errorRateWriter.Write(&Log{Level: "ERROR"})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if total := math.Round(errorRateWriter.Rate() * time.Hour.Seconds()); total != 8000 {
+		t.Errorf("counted %v errors, want 8000", total)
+	}
+}
@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFieldValue(t *testing.T) {
+	tests := []struct {
+		field Field
+		want  interface{}
+	}{
+		{Str("s", "text"), "text"},
+		{Int("i", -3), -3},
+		{Int64("i64", 1<<40), int64(1 << 40)},
+		{Float("f", 0.25), 0.25},
+		{Bool("b", true), true},
+		{Bool("b", false), false},
+		{Dur("d", time.Second), time.Second},
+		{Any("a", uint8(7)), uint8(7)},
+	}
+
+	for _, test := range tests {
+		if got := test.field.Value(); got != test.want {
+			t.Errorf("%s.Value() = %#v, want %#v", test.field.Key, got, test.want)
+		}
+	}
+
+	if attrs := fieldAttrs([]Field{Err(nil), Str("url", "/")}); len(*attrs) != 1 {
+		t.Errorf("fieldAttrs kept a field without a key: %v", *attrs)
+	}
+}
+
+func benchmarkLogger() *Logger {
+	logger := New("bench")
+	logger.Writer = NullWriter{}
+	return logger
+}
+
+func BenchmarkInfo(b *testing.B) {
+	logger := benchmarkLogger()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.Info("Fetched page", Attrs{"url": "/index.html", "status": 200, "elapsed": time.Millisecond})
+	}
+}
+
+func BenchmarkInfoKeyValues(b *testing.B) {
+	logger := benchmarkLogger()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.Info("Fetched page", "url", "/index.html", "status", 200, "elapsed", time.Millisecond)
+	}
+}
+
+func BenchmarkInfoFields(b *testing.B) {
+	logger := benchmarkLogger()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.InfoFields("Fetched page", Str("url", "/index.html"), Int("status", 200), Dur("elapsed", time.Millisecond))
+	}
+}
@@ -0,0 +1,264 @@
+package logger
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileOptions configures a FileWriter.
+type FileOptions struct {
+	// FlushInterval is the longest buffered logs wait before being written to the
+	// file. Defaults to one second.
+	FlushInterval time.Duration
+
+	// FlushBytes forces a flush as soon as this many bytes are buffered, so bursts
+	// are persisted promptly. Defaults to 64KiB.
+	FlushBytes int
+
+	// MaxSize rotates the file once it reaches this many bytes: it's renamed to
+	// path.1, the previous path.1 to path.2 and so on, and a new file is started.
+	// Zero disables rotation by size; Rotate still works.
+	MaxSize int64
+
+	// MaxBackups is the number of rotated files kept. Defaults to 3.
+	MaxBackups int
+
+	// FlushLevel is the level from which logs are written to the file right away,
+	// before Write returns, so that they survive a crash following them, while
+	// less important logs are batched. Defaults to ERROR; "none" only flushes by
+	// size and interval.
+	FlushLevel string
+}
+
+// FileWriter appends logs to a file through a buffer, flushing it when either
+// FlushBytes are buffered or FlushInterval has passed since the first buffered
+// log, whichever comes first, and right away for logs at FlushLevel and above.
+// Logs are filtered and formatted like the standard output, following the LOG
+// and LOG_FORMAT envvars, and default to JSON. See MaxSize for rotating the file.
+type FileWriter struct {
+	Formatter *StandardWriter
+
+	opts   FileOptions
+	path   string
+	mutex  sync.Mutex
+	file   *os.File
+	size   int64
+	buffer *bufio.Writer
+	timer  *time.Timer
+	closed bool
+
+	// retryRotate is when rotating by size is attempted again after a failure.
+	retryRotate time.Time
+}
+
+// NewFileWriter opens the file at path for appending, creating it if needed.
+func NewFileWriter(path string, opts FileOptions) (*FileWriter, error) {
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+
+	if opts.FlushBytes <= 0 {
+		opts.FlushBytes = 64 * 1024
+	}
+
+	if opts.MaxBackups <= 0 {
+		opts.MaxBackups = 3
+	}
+
+	if opts.FlushLevel == "" {
+		opts.FlushLevel = "ERROR"
+	}
+
+	file, size, err := openLogFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	formatter := NewStandardOutput(file).(StandardWriter)
+
+	return &FileWriter{
+		Formatter: &formatter,
+		opts:      opts,
+		path:      path,
+		file:      file,
+		size:      size,
+		buffer:    bufio.NewWriterSize(file, opts.FlushBytes),
+	}, nil
+}
+
+// openLogFile opens the file at path for appending, returning its current size.
+func openLogFile(path string) (*os.File, int64, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, err
+	}
+
+	return file, stat.Size(), nil
+}
+
+func (fileWriter *FileWriter) Init() {}
+
+func (fileWriter *FileWriter) Write(log *Log) {
+	fileWriter.TryWrite(log)
+}
+
+// TryWrite is like Write, returning the error of flushing or rotating the file,
+// or os.ErrClosed once the writer is closed.
+func (fileWriter *FileWriter) TryWrite(log *Log) error {
+	if !log.notice && !fileWriter.Formatter.IsEnabled(log.Package, log.Level) {
+		return nil
+	}
+
+	line := fileWriter.Formatter.Format(log)
+
+	fileWriter.mutex.Lock()
+	defer fileWriter.mutex.Unlock()
+
+	if fileWriter.closed {
+		return os.ErrClosed
+	}
+
+	if !fileWriter.Formatter.NoNewline {
+		line += "\n"
+	}
+
+	fileWriter.buffer.WriteString(line)
+	fileWriter.size += int64(len(line))
+
+	if fileWriter.opts.MaxSize > 0 && fileWriter.size >= fileWriter.opts.MaxSize && !time.Now().Before(fileWriter.retryRotate) {
+		err := fileWriter.rotate()
+		if err == nil {
+			return nil
+		}
+
+		// Keep appending to the current file, retrying a little later rather
+		// than on every log.
+		fileWriter.retryRotate = time.Now().Add(fileWriter.opts.FlushInterval)
+		return err
+	}
+
+	if fileWriter.buffer.Buffered() >= fileWriter.opts.FlushBytes {
+		return fileWriter.flush()
+	}
+
+	if flushLevel := severity(fileWriter.opts.FlushLevel); flushLevel > 0 && severity(log.Level) >= flushLevel {
+		return fileWriter.flush()
+	}
+
+	// Arm the timer only while there is something to flush, so that idle
+	// periods don't cost anything.
+	if fileWriter.timer == nil {
+		fileWriter.timer = time.AfterFunc(fileWriter.opts.FlushInterval, func() {
+			fileWriter.Flush()
+		})
+	}
+
+	return nil
+}
+
+// Flush writes the buffered logs to the file.
+func (fileWriter *FileWriter) Flush() error {
+	fileWriter.mutex.Lock()
+	defer fileWriter.mutex.Unlock()
+
+	if fileWriter.closed {
+		return os.ErrClosed
+	}
+
+	return fileWriter.flush()
+}
+
+// Close flushes the buffered logs and closes the file. Later writes are dropped.
+func (fileWriter *FileWriter) Close() error {
+	fileWriter.mutex.Lock()
+	defer fileWriter.mutex.Unlock()
+
+	if fileWriter.closed {
+		return os.ErrClosed
+	}
+	fileWriter.closed = true
+
+	// flush also stops the timer.
+	if err := fileWriter.flush(); err != nil {
+		fileWriter.file.Close()
+		return err
+	}
+
+	return fileWriter.file.Close()
+}
+
+func (fileWriter *FileWriter) flush() error {
+	if fileWriter.timer != nil {
+		fileWriter.timer.Stop()
+		fileWriter.timer = nil
+	}
+
+	return fileWriter.buffer.Flush()
+}
+
+// Rotate rotates the file now, whatever its size, e.g. on SIGUSR1 or before a
+// backup job. Logs written meanwhile wait for the new file.
+func (fileWriter *FileWriter) Rotate() error {
+	fileWriter.mutex.Lock()
+	defer fileWriter.mutex.Unlock()
+
+	if fileWriter.closed {
+		return os.ErrClosed
+	}
+
+	return fileWriter.rotate()
+}
+
+// rotate flushes the buffer and moves the current file aside, shifting the older
+// backups, before starting a new file. The new file is created first, so that
+// nothing is renamed if it can't be, e.g. in a read-only directory.
+func (fileWriter *FileWriter) rotate() error {
+	if err := fileWriter.flush(); err != nil {
+		return err
+	}
+
+	next := fileWriter.path + ".next"
+	file, err := os.OpenFile(next, os.O_WRONLY|os.O_APPEND|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	backup := func(n int) string {
+		return fmt.Sprintf("%s.%d", fileWriter.path, n)
+	}
+
+	os.Remove(backup(fileWriter.opts.MaxBackups))
+	for n := fileWriter.opts.MaxBackups - 1; n > 0; n-- {
+		os.Rename(backup(n), backup(n+1))
+	}
+
+	if err := os.Rename(fileWriter.path, backup(1)); err != nil {
+		file.Close()
+		os.Remove(next)
+		return err
+	}
+
+	if err := os.Rename(next, fileWriter.path); err != nil {
+		os.Rename(backup(1), fileWriter.path)
+		file.Close()
+		os.Remove(next)
+		return err
+	}
+
+	fileWriter.file.Close()
+	fileWriter.file = file
+	fileWriter.size = 0
+	fileWriter.buffer.Reset(file)
+	fileWriter.Formatter.Target = file
+
+	return nil
+}
@@ -0,0 +1,246 @@
+package logger
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestFileWriter returns a file writer to app.log in a temporary directory,
+// showing every level.
+func newTestFileWriter(t *testing.T, opts FileOptions) (*FileWriter, string) {
+	dir, err := ioutil.TempDir("", "logger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.Chmod(dir, 0755)
+		os.RemoveAll(dir)
+	})
+
+	path := filepath.Join(dir, "app.log")
+	fileWriter, err := NewFileWriter(path, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { fileWriter.Close() })
+
+	fileWriter.Formatter.Settings = map[string]*OutputSettings{"*": {Debug: true, Info: true, Timer: true, Warn: true, Error: true}}
+
+	return fileWriter, path
+}
+
+func readFile(t *testing.T, path string) string {
+	content, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestFileWriterRotationFailure(t *testing.T) {
+	fileWriter, path := newTestFileWriter(t, FileOptions{MaxSize: 10, MaxBackups: 2})
+
+	if err := ioutil.WriteFile(path+".1", []byte("backup"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The new file can't be created where it's expected.
+	if err := os.Mkdir(path+".next", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fileWriter.TryWrite(&Log{Level: "INFO", Message: "rotate"}); err == nil {
+		t.Error("TryWrite didn't report the failed rotation")
+	}
+
+	for i := 0; i < 3; i++ {
+		fileWriter.TryWrite(&Log{Level: "INFO", Message: "more"})
+	}
+
+	if backup := readFile(t, path+".1"); backup != "backup" {
+		t.Errorf("path.1 = %q, want the backup kept", backup)
+	}
+
+	if _, err := os.Stat(path + ".2"); !os.IsNotExist(err) {
+		t.Errorf("path.2 was created by the failed rotation: %v", err)
+	}
+
+	fileWriter.Flush()
+	if content := readFile(t, path); content == "" {
+		t.Error("the logs were lost instead of appended to the current file")
+	}
+}
+
+func TestFileWriterUnwritableDirectory(t *testing.T) {
+	fileWriter, path := newTestFileWriter(t, FileOptions{MaxSize: 10, MaxBackups: 2})
+
+	if err := ioutil.WriteFile(path+".1", []byte("backup"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.Chmod(dir, 0555); err != nil {
+		t.Fatal(err)
+	}
+
+	if probe, err := os.Create(filepath.Join(dir, "probe")); err == nil {
+		probe.Close()
+		t.Skip("the directory is still writable, e.g. when running as root")
+	}
+
+	if err := fileWriter.TryWrite(&Log{Level: "INFO", Message: "rotate"}); err == nil {
+		t.Error("TryWrite didn't report the failed rotation")
+	}
+
+	if backup := readFile(t, path+".1"); backup != "backup" {
+		t.Errorf("path.1 = %q, want the backup kept", backup)
+	}
+}
+
+func TestFileWriterFlushBytes(t *testing.T) {
+	fileWriter, path := newTestFileWriter(t, FileOptions{FlushBytes: 200, FlushInterval: time.Hour})
+
+	fileWriter.Write(&Log{Level: "INFO", Message: "buffered"})
+	if content := readFile(t, path); content != "" {
+		t.Errorf("a small log was flushed right away: %q", content)
+	}
+
+	for i := 0; i < 5; i++ {
+		fileWriter.Write(&Log{Level: "INFO", Message: "filling up the buffer"})
+	}
+	if content := readFile(t, path); !strings.Contains(content, "buffered") {
+		t.Errorf("the buffer wasn't flushed past FlushBytes: %q", content)
+	}
+}
+
+func TestFileWriterFlushLevel(t *testing.T) {
+	tests := []struct {
+		flushLevel string
+		level      string
+		flushed    bool
+	}{
+		{"", "INFO", false},
+		{"", "WARN", false},
+		{"", "ERROR", true},
+		{"WARN", "WARN", true},
+		{"WARN", "INFO", false},
+		{"none", "ERROR", false},
+	}
+
+	for _, test := range tests {
+		fileWriter, path := newTestFileWriter(t, FileOptions{FlushInterval: time.Hour, FlushLevel: test.flushLevel})
+
+		fileWriter.Write(&Log{Level: test.level, Message: "important"})
+		if flushed := readFile(t, path) != ""; flushed != test.flushed {
+			t.Errorf("FlushLevel %q: %s log flushed = %v, want %v", test.flushLevel, test.level, flushed, test.flushed)
+		}
+	}
+}
+
+func TestFileWriterFlushInterval(t *testing.T) {
+	fileWriter, path := newTestFileWriter(t, FileOptions{FlushInterval: 10 * time.Millisecond})
+
+	fileWriter.Write(&Log{Level: "INFO", Message: "later"})
+
+	deadline := time.Now().Add(time.Second)
+	for readFile(t, path) == "" && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if readFile(t, path) == "" {
+		t.Error("the log wasn't flushed after FlushInterval")
+	}
+}
+
+func TestFileWriterMaxBackups(t *testing.T) {
+	fileWriter, path := newTestFileWriter(t, FileOptions{MaxSize: 50, MaxBackups: 2})
+
+	for i := 0; i < 10; i++ {
+		fileWriter.Write(&Log{Level: "INFO", Message: fmt.Sprintf("log %d", i)})
+	}
+	fileWriter.Flush()
+
+	for _, name := range []string{path + ".1", path + ".2"} {
+		if readFile(t, name) == "" {
+			t.Errorf("%s is missing", filepath.Base(name))
+		}
+	}
+
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("more than MaxBackups files are kept: %v", err)
+	}
+
+	if !strings.Contains(readFile(t, path+".1"), "log 9") {
+		t.Errorf("path.1 = %q, want the latest logs", readFile(t, path+".1"))
+	}
+}
+
+func TestFileWriterRotateConcurrently(t *testing.T) {
+	fileWriter, path := newTestFileWriter(t, FileOptions{MaxBackups: 1000})
+
+	const writers, logs = 4, 100
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < logs; i++ {
+				fileWriter.Write(&Log{Level: "INFO", Message: "concurrent"})
+			}
+		}()
+	}
+
+	rotations := 0
+	for i := 0; i < 20; i++ {
+		if err := fileWriter.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+		rotations++
+	}
+
+	wg.Wait()
+	if err := fileWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Count(readFile(t, path), "\n")
+	for n := 1; n <= rotations; n++ {
+		lines += strings.Count(readFile(t, fmt.Sprintf("%s.%d", path, n)), "\n")
+	}
+
+	if lines != writers*logs {
+		t.Errorf("found %d logs across the files, want %d", lines, writers*logs)
+	}
+}
+
+func TestFileWriterClosed(t *testing.T) {
+	fileWriter, path := newTestFileWriter(t, FileOptions{FlushInterval: 10 * time.Millisecond})
+
+	fileWriter.Write(&Log{Level: "INFO", Message: "before"})
+	if err := fileWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fileWriter.TryWrite(&Log{Level: "INFO", Message: "after"}); err != os.ErrClosed {
+		t.Errorf("TryWrite after Close = %v, want os.ErrClosed", err)
+	}
+
+	if err := fileWriter.Flush(); err != os.ErrClosed {
+		t.Errorf("Flush after Close = %v, want os.ErrClosed", err)
+	}
+
+	if err := fileWriter.Rotate(); err != os.ErrClosed {
+		t.Errorf("Rotate after Close = %v, want os.ErrClosed", err)
+	}
+
+	if content := readFile(t, path); !strings.Contains(content, "before") || strings.Contains(content, "after") {
+		t.Errorf("file = %q, want only the log written before Close", content)
+	}
+}
@@ -0,0 +1,27 @@
+package logger
+
+// FilterWriter forwards to an inner writer only the logs for which a predicate
+// returns true; the others are silently dropped. The predicate runs before any
+// formatting, so it's cheap to drop logs this way.
+type FilterWriter struct {
+	inner OutputWriter
+	keep  func(*Log) bool
+}
+
+// NewFilterWriter wraps inner with the keep predicate.
+func NewFilterWriter(inner OutputWriter, keep func(*Log) bool) *FilterWriter {
+	return &FilterWriter{
+		inner: inner,
+		keep:  keep,
+	}
+}
+
+func (filterWriter *FilterWriter) Init() {
+	filterWriter.inner.Init()
+}
+
+func (filterWriter *FilterWriter) Write(log *Log) {
+	if filterWriter.keep(log) {
+		filterWriter.inner.Write(log)
+	}
+}
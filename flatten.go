@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// maxFlattenDepth bounds how deep Flatten expands nested values, which also
+// protects against cyclic structures.
+const maxFlattenDepth = 8
+
+// Flattened is an attribute value whose fields are expanded into separate
+// "key.field" attributes. See Flatten.
+type Flattened struct {
+	value interface{}
+	depth int
+}
+
+// Flatten marks a struct or map attribute value to be expanded one level deep, so
+// that logger.Attrs{"user": logger.Flatten(user)} logs user.id, user.name, etc.
+// Struct fields are named after their json tags when they have one.
+func Flatten(v interface{}) Flattened {
+	return Flattened{value: v, depth: 1}
+}
+
+// FlattenDepth is like Flatten, expanding nested structs and maps up to the given
+// depth.
+func FlattenDepth(v interface{}, depth int) Flattened {
+	if depth > maxFlattenDepth {
+		depth = maxFlattenDepth
+	}
+
+	return Flattened{value: v, depth: depth}
+}
+
+// Fields returns the expanded attributes, prefixed with key.
+func (flattened Flattened) Fields(key string) map[string]interface{} {
+	fields := map[string]interface{}{}
+	flatten(fields, key, reflect.ValueOf(flattened.value), flattened.depth)
+	return fields
+}
+
+func flatten(fields map[string]interface{}, key string, rv reflect.Value, depth int) {
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			fields[key] = nil
+			return
+		}
+		rv = rv.Elem()
+	}
+
+	if depth <= 0 || !rv.IsValid() || !expandable(rv) {
+		if rv.IsValid() && rv.CanInterface() {
+			fields[key] = rv.Interface()
+		} else {
+			fields[key] = nil
+		}
+		return
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		t := rv.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+
+			name := field.Name
+			if tag := field.Tag.Get("json"); tag != "" {
+				if tag == "-" {
+					continue
+				}
+				if n := strings.Split(tag, ",")[0]; n != "" {
+					name = n
+				}
+			}
+
+			flatten(fields, key+"."+name, rv.Field(i), depth-1)
+		}
+	case reflect.Map:
+		iter := rv.MapRange()
+		for iter.Next() {
+			flatten(fields, key+"."+fmt.Sprint(iter.Key().Interface()), iter.Value(), depth-1)
+		}
+	}
+}
+
+var (
+	stringerType  = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	errorType     = reflect.TypeOf((*error)(nil)).Elem()
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// expandable reports whether the value is a struct or map without a string form of
+// its own, like time.Time has.
+func expandable(rv reflect.Value) bool {
+	if rv.Kind() != reflect.Struct && rv.Kind() != reflect.Map {
+		return false
+	}
+
+	for _, t := range []reflect.Type{rv.Type(), reflect.PtrTo(rv.Type())} {
+		if t.Implements(stringerType) || t.Implements(errorType) || t.Implements(marshalerType) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,25 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+)
+
+// Formatter renders a log as a line of text. *StandardWriter implements it,
+// following its OutputFormat.
+type Formatter interface {
+	Format(log *Log) string
+}
+
+// FormatLogs writes logs to w, one per line, as rendered by f, e.g. to return
+// captured logs from a debug endpoint in the same format as the live output.
+// The logs aren't filtered.
+func FormatLogs(w io.Writer, logs []*Log, f Formatter) error {
+	for _, log := range logs {
+		if _, err := fmt.Fprintln(w, f.Format(log)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -2,61 +2,133 @@ package logger
 
 import (
 	"fmt"
+	"os"
+	"strings"
 	"sync"
 )
 
+// ANSI escapes used by the pretty output.
+const (
+	Reset   = "\033[0m"
+	Bold    = "\033[1m"
+	White   = "\033[37m"
+	Red     = "\033[31m"
+	Blue    = "\033[34m"
+	Green   = "\033[32m"
+	Cyan    = "\033[36m"
+	Yellow  = "\033[33m"
+	Magenta = "\033[35m"
+)
+
+// Palette is a list of colors handed out to the package labels in turn.
+type Palette []string
+
+// Color returns the i-th color of the palette, wrapping around.
+func (palette Palette) Color(i int) string {
+	if len(palette) == 0 {
+		return ""
+	}
+
+	return palette[i%len(palette)]
+}
+
+// DefaultPalette is the palette the package labels are colored from by ThemeDark.
+var DefaultPalette = Palette{Blue, Green, Cyan, Yellow, Magenta}
+
+// Theme is a set of colors for the pretty output, suited to a terminal background.
+type Theme struct {
+	// Palette colors the package labels. DefaultPalette is used when nil.
+	Palette Palette
+
+	// Error and Warn color the markers of ERROR and WARN logs.
+	Error string
+	Warn  string
+}
+
 var (
-	colors  sync.Map
-	white   = "\033[37m"
-	reset   = "\033[0m"
-	bold    = "\033[1m"
-	red     = "\033[31m"
-	blue    = "\033[34m"
-	green   = "\033[32m"
-	cyan    = "\033[36m"
-	yellow  = "\033[33m"
-	magenta = "\033[35m"
-
-	//colorIndex      = 0
-	//listOfColors    sync.Map
-	//colorLoggerDict sync.Map
-	/*colors          = []string{
-		"\033[34m", // blue
-		"\033[32m", // green
-		"\033[36m", // cyan
-		"\033[33m", // yellow
-		"\033[35m", // magenta
-	}*/
+	// ThemeDark suits terminals with a dark background. It's the default.
+	ThemeDark = &Theme{
+		Error: Red,
+		Warn:  Yellow,
+	}
+
+	// ThemeLight suits terminals with a light background, avoiding the yellow and
+	// cyan that are hard to read on it.
+	ThemeLight = &Theme{
+		Palette: Palette{Blue, Magenta, Green, Bold + Blue, Bold + Magenta},
+		Error:   Bold + Red,
+		Warn:    Magenta,
+	}
 )
 
+var colors = struct {
+	sync.Mutex
+	next     int
+	packages map[string]int
+	theme    *Theme
+	themes   map[string]*Theme
+}{
+	packages: map[string]int{},
+	theme:    ThemeDark,
+	themes: map[string]*Theme{
+		"dark":  ThemeDark,
+		"light": ThemeLight,
+	},
+}
+
 func init() {
-	colors.Store("index", 0)
-	colors.Store("index:0", blue)
-	colors.Store("index:1", green)
-	colors.Store("index:2", cyan)
-	colors.Store("index:3", yellow)
-	colors.Store("index:4", magenta)
-	colors.Store("len", 5)
+	if name := os.Getenv("LOG_THEME"); name != "" {
+		SetTheme(name)
+	}
 }
 
-func nextColor() string {
-	//colorIndex = colorIndex + 1
-	//return colors[colorIndex%len(colors)]
-	currentIndex, _ := colors.Load("index")
-	len, _ := colors.Load("len")
-	color, _ := colors.Load(fmt.Sprintf("index:%d", currentIndex.(int)%len.(int)))
+// RegisterTheme makes a custom theme available to SetTheme and LOG_THEME.
+func RegisterTheme(name string, theme *Theme) {
+	colors.Lock()
+	defer colors.Unlock()
 
-	colors.Store("index", currentIndex.(int)+1)
+	colors.themes[strings.ToLower(name)] = theme
+}
+
+// SetTheme activates the theme registered with the given name, "dark" or "light"
+// unless custom themes are registered. The LOG_THEME envvar sets it at startup.
+func SetTheme(name string) error {
+	colors.Lock()
+	defer colors.Unlock()
+
+	theme, ok := colors.themes[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return fmt.Errorf("logger: unknown theme %q", name)
+	}
 
-	return color.(string)
+	colors.theme = theme
+	return nil
 }
 
+func activeTheme() *Theme {
+	colors.Lock()
+	defer colors.Unlock()
+
+	return colors.theme
+}
+
+// colorFor returns the color of the given package. Packages are assigned the
+// colors of the active theme's palette in turn, the first time they're seen.
 func colorFor(key string) string {
-	if color, ok := colors.Load(fmt.Sprintf("module:%s", key)); ok {
-		return color.(string)
+	colors.Lock()
+	defer colors.Unlock()
+
+	index, ok := colors.packages[key]
+	if !ok {
+		index = colors.next
+		colors.next++
+		colors.packages[key] = index
+	}
+
+	palette := colors.theme.Palette
+	if palette == nil {
+		palette = DefaultPalette
 	}
 
-	color := nextColor()
-	colors.Store(fmt.Sprintf("module:%s", key), color)
-	return color
+	return palette.Color(index)
 }
@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"bytes"
+	goruntime "runtime"
+	"runtime/debug"
+	"strconv"
+)
+
+// GoroutineKey is the attribute holding the ID of the goroutine that logged, for
+// loggers with ShowGoroutineID set.
+const GoroutineKey = "goroutine"
+
+// RepanicInGo makes the goroutines started by Go panic again once the panic is
+// logged, crashing the program as an unrecovered panic would. Off by default, so
+// that a panicking goroutine just exits.
+var RepanicInGo = false
+
+// Go runs f in a new goroutine, logging any panic at ERROR through l, with the
+// stack trace as the "stack" attribute, instead of letting it go unnoticed or
+// crash the program.
+func Go(l *Logger, f func()) {
+	go func() {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+
+			l.Error("Goroutine panicked: %v", r, Attrs{StackKey: string(debug.Stack())})
+
+			if RepanicInGo {
+				panic(r)
+			}
+		}()
+
+		f()
+	}()
+}
+
+// goroutineID returns the ID of the current goroutine, read from the header of its
+// stack trace, "goroutine 42 [running]:", or 0 if it can't be parsed.
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:goruntime.Stack(buf[:], false)]
+
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+
+	id, err := strconv.ParseUint(string(b), 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return id
+}
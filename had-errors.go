@@ -0,0 +1,30 @@
+package logger
+
+import "sync/atomic"
+
+// hadErrors is set once an error has been logged. See HadErrors.
+var hadErrors int32
+
+// trackErrors records whether the log is an ERROR, or one of the custom levels
+// above it.
+func trackErrors(log *Log) {
+	if severity(log.Level) >= severity("ERROR") {
+		atomic.StoreInt32(&hadErrors, 1)
+	}
+}
+
+// HadErrors reports whether anything was logged at ERROR (or a CRITICAL or FATAL
+// custom level) since the program started or ResetErrors was called, whether or
+// not it was shown. CLIs can use it for their exit code:
+//
+//	if logger.HadErrors() {
+//		os.Exit(1)
+//	}
+func HadErrors() bool {
+	return atomic.LoadInt32(&hadErrors) == 1
+}
+
+// ResetErrors clears the flag reported by HadErrors.
+func ResetErrors() {
+	atomic.StoreInt32(&hadErrors, 0)
+}
@@ -0,0 +1,162 @@
+// Package journald provides an output writer sending logs to the systemd journal
+// through its native protocol, preserving attributes as journal fields.
+package journald
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/STRUCTiX/logger"
+)
+
+// SocketPath is where journald listens for native protocol messages.
+const SocketPath = "/run/systemd/journal/socket"
+
+// reserved are the fields set by the writer itself, which attributes can't
+// override.
+var reserved = map[string]bool{
+	"MESSAGE":      true,
+	"PRIORITY":     true,
+	"LOGGER":       true,
+	"LOGGER_LEVEL": true,
+	"ELAPSED":      true,
+}
+
+// Syslog priorities, as expected in the PRIORITY journal field.
+var priorities = map[string]int{
+	"ERROR": 3,
+	"WARN":  4,
+	"INFO":  6,
+	"TIMER": 6,
+	"DEBUG": 7,
+}
+
+// Writer sends every log to journald, with MESSAGE, PRIORITY and the logger's
+// name as LOGGER, plus each attribute as an uppercased journal field. Attributes
+// whose field would start with a digit or clash with the fields set by the writer
+// are prefixed with ATTR_.
+type Writer struct {
+	conn *net.UnixConn
+}
+
+// NewWriter connects to the journal, returning an error when not running under
+// systemd.
+func NewWriter() (*Writer, error) {
+	if _, err := os.Stat(SocketPath); err != nil {
+		return nil, errors.New("journald: not running under systemd")
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: SocketPath, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("journald: %v", err)
+	}
+
+	return &Writer{conn: conn}, nil
+}
+
+func (writer *Writer) Init() {}
+
+func (writer *Writer) Write(log *logger.Log) {
+	writer.TryWrite(log)
+}
+
+// TryWrite is like Write, returning the error of sending the log, e.g. EMSGSIZE
+// when it's too large for a datagram.
+func (writer *Writer) TryWrite(log *logger.Log) error {
+	if _, err := writer.conn.Write(Encode(log)); err != nil {
+		return fmt.Errorf("journald: %v", err)
+	}
+
+	return nil
+}
+
+// Close closes the connection to the journal.
+func (writer *Writer) Close() error {
+	return writer.conn.Close()
+}
+
+// Encode serializes the log as a journald native protocol message.
+func Encode(log *logger.Log) []byte {
+	var buf bytes.Buffer
+
+	priority, ok := priorities[log.Level]
+	if !ok {
+		priority = priorities["INFO"]
+	}
+
+	writeField(&buf, "MESSAGE", log.Message)
+	writeField(&buf, "PRIORITY", fmt.Sprintf("%d", priority))
+	writeField(&buf, "LOGGER", log.Package)
+	writeField(&buf, "LOGGER_LEVEL", log.Level)
+
+	if log.Level == "TIMER" {
+		writeField(&buf, "ELAPSED", time.Duration(log.ElapsedNano).String())
+	}
+
+	if log.Attrs != nil {
+		keys := make([]string, 0, len(*log.Attrs))
+		for key := range *log.Attrs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if name := fieldName(key); name != "" {
+				writeField(&buf, name, fmt.Sprintf("%v", (*log.Attrs)[key]))
+			}
+		}
+	}
+
+	return buf.Bytes()
+}
+
+func writeField(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+
+	if !strings.Contains(value, "\n") {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+
+	// Multi-line values are sent as the name, a newline, the little-endian 64-bit
+	// length of the value and the value itself.
+	buf.WriteByte('\n')
+	binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// fieldName converts an attribute key into a valid journal field name: uppercase
+// letters, digits and underscores, starting with a letter, and not one of the
+// reserved fields.
+func fieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, key)
+
+	name = strings.TrimLeft(name, "_")
+	if name == "" {
+		return ""
+	}
+
+	if name[0] >= '0' && name[0] <= '9' || reserved[name] {
+		return "ATTR_" + name
+	}
+
+	return name
+}
@@ -0,0 +1,79 @@
+package journald
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/STRUCTiX/logger"
+)
+
+func TestEncodePriority(t *testing.T) {
+	tests := []struct {
+		level    string
+		priority string
+	}{
+		{"ERROR", "PRIORITY=3\n"},
+		{"WARN", "PRIORITY=4\n"},
+		{"INFO", "PRIORITY=6\n"},
+		{"TIMER", "PRIORITY=6\n"},
+		{"DEBUG", "PRIORITY=7\n"},
+		{"AUDIT", "PRIORITY=6\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.level, func(t *testing.T) {
+			got := Encode(&logger.Log{Package: "db", Level: test.level, Message: "hello"})
+
+			if !bytes.Contains(got, []byte(test.priority)) {
+				t.Errorf("Encode() = %q, want it to contain %q", got, test.priority)
+			}
+		})
+	}
+}
+
+func TestEncodeAttrs(t *testing.T) {
+	got := Encode(&logger.Log{
+		Package: "db",
+		Level:   "INFO",
+		Message: "hello",
+		Attrs: &logger.Attrs{
+			"user-id":  42,
+			"2fa":      true,
+			"message":  "shadowed",
+			"priority": 1,
+			"__":       "dropped",
+		},
+	})
+
+	want := "MESSAGE=hello\n" +
+		"PRIORITY=6\n" +
+		"LOGGER=db\n" +
+		"LOGGER_LEVEL=INFO\n" +
+		"ATTR_2FA=true\n" +
+		"ATTR_MESSAGE=shadowed\n" +
+		"ATTR_PRIORITY=1\n" +
+		"USER_ID=42\n"
+
+	if string(got) != want {
+		t.Errorf("Encode() = %q, want %q", got, want)
+	}
+}
+
+func TestEncodeMultiLine(t *testing.T) {
+	message := "first line\nsecond line"
+	got := Encode(&logger.Log{Package: "db", Level: "ERROR", Message: message})
+
+	var want bytes.Buffer
+	want.WriteString("MESSAGE\n")
+	binary.Write(&want, binary.LittleEndian, uint64(len(message)))
+	want.WriteString(message + "\n")
+
+	if !bytes.HasPrefix(got, want.Bytes()) {
+		t.Errorf("Encode() = %q, want it to start with %q", got, want.Bytes())
+	}
+
+	if !bytes.Contains(got, []byte("\nPRIORITY=3\n")) {
+		t.Errorf("Encode() = %q, want the fields after the message to follow its length-prefixed value", got)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !go1.22
+// +build !go1.22
+
+package logger
+
+// shortControlEscapes is whether encoding/json escapes '\b' and '\f' as such.
+// Before Go 1.22, it writes them as \u0008 and \u000c.
+const shortControlEscapes = false
@@ -0,0 +1,8 @@
+//go:build go1.22
+// +build go1.22
+
+package logger
+
+// shortControlEscapes is whether encoding/json escapes '\b' and '\f' as such,
+// which it does since Go 1.22.
+const shortControlEscapes = true
@@ -0,0 +1,342 @@
+package logger
+
+import (
+	"bytes"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// maxKeyOrders bounds the number of attribute key sets whose sorted order is
+// cached.
+const maxKeyOrders = 1024
+
+var (
+	bufferPool = sync.Pool{
+		New: func() interface{} {
+			return new(bytes.Buffer)
+		},
+	}
+
+	// keyOrders caches the sorted keys of attribute sets by a hash of their keys,
+	// as logs from the same call site or child logger tend to share them.
+	keyOrders     sync.Map
+	keyOrdersSize int32
+	keyOrdersLock sync.Mutex
+)
+
+// sortedKeys returns the keys of attrs in sorted order. The returned slice is
+// shared and must not be modified.
+func sortedKeys(attrs map[string]interface{}) []string {
+	var sum uint64
+	for key := range attrs {
+		sum += fnv64a(key)
+	}
+
+	if cached, ok := keyOrders.Load(sum); ok {
+		keys := cached.([]string)
+		if sameKeys(keys, attrs) {
+			return keys
+		}
+	}
+
+	keys := make([]string, 0, len(attrs))
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	keyOrdersLock.Lock()
+	if keyOrdersSize < maxKeyOrders {
+		if _, loaded := keyOrders.LoadOrStore(sum, keys); !loaded {
+			keyOrdersSize++
+		}
+	}
+	keyOrdersLock.Unlock()
+
+	return keys
+}
+
+// fnv64a returns the FNV-1a hash of key, computed inline to avoid allocating a
+// hasher and a byte slice per key.
+func fnv64a(key string) uint64 {
+	hash := uint64(14695981039346656037)
+	for i := 0; i < len(key); i++ {
+		hash ^= uint64(key[i])
+		hash *= 1099511628211
+	}
+
+	return hash
+}
+
+func sameKeys(keys []string, attrs map[string]interface{}) bool {
+	if len(keys) != len(attrs) {
+		return false
+	}
+
+	for _, key := range keys {
+		if _, ok := attrs[key]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+const hex = "0123456789abcdef"
+
+// writeJSONString writes s as a JSON string, escaped the same way as by
+// encoding/json.
+func writeJSONString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+
+			buf.WriteString(s[start:i])
+			switch c {
+			case '"', '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(c)
+			case '\n':
+				buf.WriteString(`\n`)
+			case '\r':
+				buf.WriteString(`\r`)
+			case '\t':
+				buf.WriteString(`\t`)
+			case '\b':
+				if shortControlEscapes {
+					buf.WriteString(`\b`)
+				} else {
+					writeControlEscape(buf, c)
+				}
+			case '\f':
+				if shortControlEscapes {
+					buf.WriteString(`\f`)
+				} else {
+					writeControlEscape(buf, c)
+				}
+			default:
+				writeControlEscape(buf, c)
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf.WriteString(s[start:i])
+			buf.WriteString("\ufffd")
+			i += size
+			start = i
+			continue
+		}
+
+		if r == '\u2028' || r == '\u2029' {
+			buf.WriteString(s[start:i])
+			buf.WriteString(`\u202`)
+			buf.WriteByte(hex[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+
+		i += size
+	}
+
+	buf.WriteString(s[start:])
+	buf.WriteByte('"')
+}
+
+func writeControlEscape(buf *bytes.Buffer, c byte) {
+	buf.WriteString(`\u00`)
+	buf.WriteByte(hex[c>>4])
+	buf.WriteByte(hex[c&0xF])
+}
+
+// writeLogJSON encodes the log the same way as json.Marshal would, without going
+// through reflection, naming the level field levelKey. If jsonTime isn't empty,
+// it replaces the numeric time and is written last, as in the output of the
+// JSONTimeFormat wrapper.
+func writeLogJSON(buf *bytes.Buffer, log *Log, attrs *Attrs, jsonTime, levelKey string) error {
+	var scratch [64]byte
+
+	buf.WriteString(`{"package":`)
+	writeJSONString(buf, log.Package)
+	buf.WriteByte(',')
+	writeJSONString(buf, levelKey)
+	buf.WriteByte(':')
+	writeJSONString(buf, log.Level)
+	buf.WriteString(`,"msg":`)
+	writeJSONString(buf, log.Message)
+
+	if log.Code != "" {
+		buf.WriteString(`,"code":`)
+		writeJSONString(buf, log.Code)
+	}
+
+	if attrs != nil {
+		buf.WriteString(`,"attrs":`)
+		writeAttrsJSON(buf, *attrs)
+	}
+
+	if jsonTime == "" {
+		buf.WriteString(`,"time":`)
+		buf.Write(strconv.AppendInt(scratch[:0], log.Time, 10))
+	}
+
+	if log.Elapsed != 0 {
+		buf.WriteString(`,"elapsed":`)
+		buf.Write(strconv.AppendInt(scratch[:0], log.Elapsed, 10))
+	}
+
+	if log.ElapsedNano != 0 {
+		buf.WriteString(`,"elapsed_nano":`)
+		buf.Write(strconv.AppendInt(scratch[:0], log.ElapsedNano, 10))
+	}
+
+	buf.WriteString(`,"seq":`)
+	buf.Write(strconv.AppendUint(scratch[:0], log.Seq, 10))
+
+	if err := writeTimeJSON(buf, `,"started_at":`, log.StartedAt); err != nil {
+		return err
+	}
+
+	if err := writeTimeJSON(buf, `,"ended_at":`, log.EndedAt); err != nil {
+		return err
+	}
+
+	if log.SourcePackage != "" {
+		buf.WriteString(`,"source_pkg":`)
+		writeJSONString(buf, log.SourcePackage)
+	}
+
+	if jsonTime != "" {
+		buf.WriteString(`,"time":`)
+		writeJSONString(buf, jsonTime)
+	}
+
+	buf.WriteByte('}')
+	return nil
+}
+
+func writeTimeJSON(buf *bytes.Buffer, field string, t *time.Time) error {
+	if t == nil {
+		return nil
+	}
+
+	raw, err := t.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	buf.WriteString(field)
+	buf.Write(raw)
+	return nil
+}
+
+// writeAttrsJSON encodes attrs with sorted keys, writing simple values directly
+// and passing the others to marshalAttr.
+func writeAttrsJSON(buf *bytes.Buffer, attrs Attrs) {
+	buf.WriteByte('{')
+	for i, key := range sortedKeys(attrs) {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		writeJSONString(buf, key)
+		buf.WriteByte(':')
+
+		if !writeSimpleJSON(buf, attrs[key]) {
+			buf.Write(marshalAttr(attrs[key]))
+		}
+	}
+	buf.WriteByte('}')
+}
+
+// writeSimpleJSON writes strings, booleans, numbers and nil the way json.Marshal
+// does, and reports false for any other value.
+func writeSimpleJSON(buf *bytes.Buffer, val interface{}) bool {
+	var scratch [64]byte
+
+	switch v := val.(type) {
+	case nil:
+		buf.WriteString("null")
+	case string:
+		writeJSONString(buf, v)
+	case bool:
+		buf.Write(strconv.AppendBool(scratch[:0], v))
+	case int:
+		buf.Write(strconv.AppendInt(scratch[:0], int64(v), 10))
+	case int8:
+		buf.Write(strconv.AppendInt(scratch[:0], int64(v), 10))
+	case int16:
+		buf.Write(strconv.AppendInt(scratch[:0], int64(v), 10))
+	case int32:
+		buf.Write(strconv.AppendInt(scratch[:0], int64(v), 10))
+	case int64:
+		buf.Write(strconv.AppendInt(scratch[:0], v, 10))
+	case uint:
+		buf.Write(strconv.AppendUint(scratch[:0], uint64(v), 10))
+	case uint8:
+		buf.Write(strconv.AppendUint(scratch[:0], uint64(v), 10))
+	case uint16:
+		buf.Write(strconv.AppendUint(scratch[:0], uint64(v), 10))
+	case uint32:
+		buf.Write(strconv.AppendUint(scratch[:0], uint64(v), 10))
+	case uint64:
+		buf.Write(strconv.AppendUint(scratch[:0], v, 10))
+	case float32:
+		return writeFloatJSON(buf, float64(v), 32)
+	case float64:
+		return writeFloatJSON(buf, v, 64)
+	default:
+		return false
+	}
+
+	return true
+}
+
+// writeFloatJSON writes floats like encoding/json. NaN and infinities aren't valid
+// JSON and are left to marshalAttr.
+func writeFloatJSON(buf *bytes.Buffer, f float64, bits int) bool {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return false
+	}
+
+	var scratch [64]byte
+	buf.Write(appendFloat(scratch[:0], f, bits))
+	return true
+}
+
+// appendFloat formats floats like encoding/json: without trailing zeros, using
+// exponent notation only for very small or large values, with a minimal exponent.
+func appendFloat(b []byte, f float64, bits int) []byte {
+	abs := math.Abs(f)
+	format := byte('f')
+	if abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+
+	b = strconv.AppendFloat(b, f, format, -1, bits)
+	if format == 'e' {
+		// Clean up e-09 to e-9.
+		if n := len(b); n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+
+	return b
+}
@@ -0,0 +1,62 @@
+//go:build go1.18
+// +build go1.18
+
+package logger
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+func FuzzJSONFormat(f *testing.F) {
+	f.Add("main", "INFO", "hello", "user", "alice", int64(42), 0.5, true, int64(0))
+	f.Add("", "", "", "", "", int64(0), 0.0, false, int64(0))
+	f.Add("db", "ERROR", "<a href=\"x\">&</a> ", "key\n", "\xff\x00", int64(math.MinInt64), 1e21, false, int64(time.Second))
+	f.Add("pkg", "TIMER", "took", "é", "\\\"", int64(1)<<53+1, 1e-7, true, int64(-1))
+
+	standardWriter := &StandardWriter{}
+
+	f.Fuzz(func(t *testing.T, pkg, level, message, key, str string, integer int64, float float64, boolean bool, elapsed int64) {
+		if math.IsNaN(float) || math.IsInf(float, 0) {
+			t.Skip()
+		}
+
+		started := time.Unix(0, integer).UTC()
+		log := &Log{
+			Package:     pkg,
+			Level:       level,
+			Message:     message,
+			Time:        integer,
+			ElapsedNano: elapsed,
+			StartedAt:   &started,
+			Attrs: &Attrs{
+				key:        str,
+				"integer":  integer,
+				"float":    float,
+				"float32":  float32(float),
+				"boolean":  boolean,
+				"nil":      nil,
+				"unsigned": uint64(integer),
+			},
+		}
+
+		got := standardWriter.JSONFormat(log)
+
+		formatted := *log
+		formatted.Attrs = standardWriter.jsonAttrs(log.Attrs)
+		want, err := json.Marshal(&formatted)
+		if err != nil {
+			t.Skip()
+		}
+
+		if got != string(want) {
+			t.Errorf("JSONFormat = %s\njson.Marshal = %s", got, want)
+		}
+
+		if !json.Valid([]byte(got)) {
+			t.Errorf("JSONFormat = %s, not valid JSON", got)
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"hash/fnv"
+	"math"
+	"strings"
+	"testing"
+)
+
+var benchmarkAttrs = map[string]interface{}{
+	"user":     "alice",
+	"request":  "GET /index.html",
+	"status":   200,
+	"bytes":    5120,
+	"duration": 0.25,
+	"cached":   true,
+}
+
+func TestFNV64a(t *testing.T) {
+	for _, key := range []string{"", "user", "request_id", "ü"} {
+		hash := fnv.New64a()
+		hash.Write([]byte(key))
+
+		if got, want := fnv64a(key), hash.Sum64(); got != want {
+			t.Errorf("fnv64a(%q) = %d, want %d", key, got, want)
+		}
+	}
+}
+
+func TestJSONOmitsEmptyFields(t *testing.T) {
+	absent := []string{`"attrs"`, `"elapsed"`, `"elapsed_nano"`, `"started_at"`, `"ended_at"`, `"code"`, `"source_pkg"`}
+
+	log := &Log{Package: "main", Level: "INFO", Message: "no attrs", Time: 1}
+	marshalled, err := json.Marshal(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	empty := &Log{Package: "main", Level: "INFO", Message: "empty attrs", Time: 1, Attrs: &Attrs{}}
+
+	for _, output := range []string{(&StandardWriter{}).JSONFormat(log), string(marshalled), (&StandardWriter{}).JSONFormat(empty)} {
+		for _, key := range absent {
+			if strings.Contains(output, key) {
+				t.Errorf("%s contains %s", output, key)
+			}
+		}
+	}
+
+	timer := &Log{Package: "main", Level: "TIMER", Message: "done", Time: 1, Elapsed: 2, ElapsedNano: 2000000}
+	output := (&StandardWriter{}).JSONFormat(timer)
+	if !strings.Contains(output, `"elapsed":2,`) || !strings.Contains(output, `"elapsed_nano":2000000`) {
+		t.Errorf("%s is missing the elapsed fields of a timer", output)
+	}
+}
+
+func TestJSONLargeIntegers(t *testing.T) {
+	attrs := Attrs{
+		"above":   int64(1)<<53 + 1,
+		"max":     int64(math.MaxInt64),
+		"min":     int64(math.MinInt64),
+		"umax":    uint64(math.MaxUint64),
+		"amount":  1234.5,
+		"integer": 9007199254740993.0,
+	}
+	log := &Log{Package: "main", Level: "INFO", Message: "numbers", Time: 1, Attrs: &attrs}
+
+	for _, standardWriter := range []*StandardWriter{{}, {FloatPrecision: 2}} {
+		output := standardWriter.JSONFormat(log)
+
+		for _, want := range []string{
+			`"above":9007199254740993`,
+			`"max":9223372036854775807`,
+			`"min":-9223372036854775808`,
+			`"umax":18446744073709551615`,
+		} {
+			if !strings.Contains(output, want) {
+				t.Errorf("FloatPrecision %d: %s doesn't contain %s", standardWriter.FloatPrecision, output, want)
+			}
+		}
+	}
+
+	output := (&StandardWriter{FloatPrecision: 2}).JSONFormat(log)
+	if !strings.Contains(output, `"amount":1234.50`) || !strings.Contains(output, `"integer":9007199254740992.00`) {
+		t.Errorf("%s doesn't render the floats with 2 decimals", output)
+	}
+}
+
+func BenchmarkSortedKeys(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sortedKeys(benchmarkAttrs)
+	}
+}
+
+func BenchmarkAttrsJSON(b *testing.B) {
+	buf := new(bytes.Buffer)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		writeAttrsJSON(buf, Attrs(benchmarkAttrs))
+	}
+}
+
+func BenchmarkAttrsMarshal(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		json.Marshal(benchmarkAttrs)
+	}
+}
+
+func benchmarkLog() *Log {
+	attrs := Attrs(benchmarkAttrs)
+	return &Log{Package: "http", Level: "INFO", Message: "request served", Time: 1, Attrs: &attrs}
+}
+
+func BenchmarkJSONFormat(b *testing.B) {
+	standardWriter := &StandardWriter{}
+	log := benchmarkLog()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		standardWriter.JSONFormat(log)
+	}
+}
+
+func BenchmarkJSONMarshal(b *testing.B) {
+	log := benchmarkLog()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		json.Marshal(log)
+	}
+}
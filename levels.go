@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+var levels = &levelRegistry{
+	levels: map[string]*customLevel{},
+	warned: map[string]bool{},
+	output: os.Stderr,
+}
+
+// builtinLevels are the levels IsEnabled knows without registration.
+var builtinLevels = []string{"DEBUG", "INFO", "TIMER", "WARN", "ERROR"}
+
+type customLevel struct {
+	enabled func(*OutputSettings) bool
+	color   string
+}
+
+type levelRegistry struct {
+	sync.RWMutex
+	levels map[string]*customLevel
+	warned map[string]bool
+	output io.Writer
+}
+
+// RegisterLevel adds a custom level such as "AUDIT" or "SECURITY". The settings
+// function decides whether the level is enabled for a given package's output
+// settings, and color is the ANSI color of its marker in pretty output. Custom
+// levels that aren't registered are always enabled and get a neutral marker.
+func RegisterLevel(name string, settings func(*OutputSettings) bool, color string) {
+	levels.Lock()
+	defer levels.Unlock()
+
+	levels.levels[strings.ToUpper(name)] = &customLevel{
+		enabled: settings,
+		color:   color,
+	}
+}
+
+func (registry *levelRegistry) Get(name string) (*customLevel, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	level, ok := registry.levels[name]
+	return level, ok
+}
+
+// WarnUnknown prints a warning to stderr the first time an unregistered level is
+// seen, so that a typo'd level doesn't go unnoticed. Levels that look like a typo
+// of a built-in one, e.g. "WRAN", get a suggestion.
+func (registry *levelRegistry) WarnUnknown(name string) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if registry.warned[name] {
+		return
+	}
+
+	registry.warned[name] = true
+
+	if builtin := typoOf(name); builtin != "" {
+		fmt.Fprintf(registry.output, "logger: unknown level %q, logging it unconditionally. Did you mean %q?\n", name, builtin)
+		return
+	}
+
+	fmt.Fprintf(registry.output, "logger: unknown level %q, logging it unconditionally. Use RegisterLevel to configure it.\n", name)
+}
+
+// typoOf returns the built-in level that name differs from by at most one edit,
+// or two for the longer names, and "" if there is none.
+func typoOf(name string) string {
+	upper := strings.ToUpper(name)
+
+	for _, builtin := range builtinLevels {
+		distance := editDistance(upper, builtin)
+		if distance <= 1 || distance == 2 && len(builtin) >= 5 {
+			return builtin
+		}
+	}
+
+	return ""
+}
+
+// editDistance returns the number of insertions, deletions, substitutions and
+// transpositions of adjacent characters turning a into b.
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = min3(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] && d[i-2][j-2]+1 < d[i][j] {
+				d[i][j] = d[i-2][j-2] + 1
+			}
+		}
+	}
+
+	return d[len(a)][len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+
+	if c < a {
+		a = c
+	}
+
+	return a
+}
+
+// severity ranks the built-in levels from DEBUG up, with the CRITICAL and FATAL
+// custom levels above ERROR. Other levels rank below DEBUG.
+func severity(level string) int {
+	switch strings.ToUpper(level) {
+	case "DEBUG":
+		return 1
+	case "INFO", "":
+		return 2
+	case "TIMER":
+		return 3
+	case "WARN", "WARNING":
+		return 4
+	case "ERROR":
+		return 5
+	case "CRITICAL", "FATAL":
+		return 6
+	}
+
+	return 0
+}
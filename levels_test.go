@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTypoOf(t *testing.T) {
+	tests := map[string]string{
+		"WARN":     "WARN",
+		"warn":     "WARN",
+		"WRAN":     "WARN",
+		"WARNN":    "WARN",
+		"ERR":      "ERROR",
+		"EROR":     "ERROR",
+		"INF0":     "INFO",
+		"DEBG":     "DEBUG",
+		"TIMERS":   "TIMER",
+		"AUDIT":    "",
+		"SECURITY": "",
+		"TRACE":    "",
+		"FATAL":    "",
+		"NOTICE":   "",
+	}
+
+	for name, want := range tests {
+		if got := typoOf(name); got != want {
+			t.Errorf("typoOf(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestWarnUnknown(t *testing.T) {
+	var output bytes.Buffer
+	registry := &levelRegistry{levels: map[string]*customLevel{}, warned: map[string]bool{}, output: &output}
+
+	registry.WarnUnknown("AUDIT")
+	registry.WarnUnknown("AUDIT")
+	if got := strings.Count(output.String(), `unknown level "AUDIT"`); got != 1 || !strings.Contains(output.String(), "RegisterLevel") {
+		t.Errorf("got %q, want one warning about AUDIT", output.String())
+	}
+
+	output.Reset()
+	registry.WarnUnknown("WRAN")
+	registry.WarnUnknown("WRAN")
+	if got := strings.Count(output.String(), `unknown level "WRAN"`); got != 1 || !strings.Contains(output.String(), `"WARN"`) {
+		t.Errorf("got %q, want one warning suggesting WARN", output.String())
+	}
+}
+
+func TestIsEnabledWarn(t *testing.T) {
+	var output bytes.Buffer
+	saved := levels.output
+	levels.output = &output
+	defer func() { levels.output = saved }()
+
+	standardWriter := &StandardWriter{Settings: map[string]*OutputSettings{
+		"quiet": {Error: true},
+		"loud":  {Warn: true, Error: true},
+	}}
+
+	for _, level := range []string{"WARN", "WARNING", "warn", "Warning"} {
+		if standardWriter.IsEnabled("quiet", level) {
+			t.Errorf("%s is enabled for a package at the error level", level)
+		}
+
+		if !standardWriter.IsEnabled("loud", level) {
+			t.Errorf("%s isn't enabled for a package at the warn level", level)
+		}
+	}
+
+	if !standardWriter.IsEnabled("quiet", "error") {
+		t.Error("error isn't enabled for a package at the error level")
+	}
+
+	if output.Len() != 0 {
+		t.Errorf("a built-in level was reported as unknown: %s", output.String())
+	}
+}
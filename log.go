@@ -11,22 +11,84 @@ type Log struct {
 	Package     string `json:"package"`
 	Level       string `json:"level"`
 	Message     string `json:"msg"`
-	Attrs       *Attrs `json:"attrs"`
+	Code        string `json:"code,omitempty"`
+	Attrs       *Attrs `json:"attrs,omitempty"`
 	Time        int64  `json:"time"`
-	Elapsed     int64  `json:"elapsed"`
-	ElapsedNano int64  `json:"elapsed_nano"`
+	Elapsed     int64  `json:"elapsed,omitempty"`
+	ElapsedNano int64  `json:"elapsed_nano,omitempty"`
+	Seq         uint64 `json:"seq"`
+
+	// StartedAt and EndedAt are set on timers, so they can be correlated like
+	// spans.
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+
+	// SourcePackage is the import path of the package that logged, for loggers
+	// with ShowSourcePackage set. It's only part of JSON output.
+	SourcePackage string `json:"source_pkg,omitempty"`
+
+	// writer is the Writer of the logger that started the timer, if any.
+	writer OutputWriter
+
+	// notice marks the logs written by the logger itself about the output, e.g.
+	// that a package was muted, which are shown whatever the package filters.
+	notice bool
 }
 
 func (log *Log) End(msg string, args ...interface{}) {
-	v, attrs := SplitAttrs(args)
-	elapsed := Now() - log.Time
+	message, attrs := formatArgs(msg, args)
+	end := time.Now()
+
+	if log.StartedAt == nil {
+		start := time.Unix(0, log.Time)
+		log.StartedAt = &start
+	}
+
+	// The duration is computed once here, so that every writer shows the same
+	// one, rather than each working it out from the timestamps.
+	elapsed := int64(end.Sub(*log.StartedAt))
+
+	if log.Attrs != nil {
+		merged := mergeAttrs(nil, *log.Attrs)
+		if attrs != nil {
+			merged = mergeAttrs(merged, *attrs)
+		}
+		attrs = &merged
+	}
 
 	log.Attrs = attrs
 	log.Elapsed = elapsed / 1000000
 	log.ElapsedNano = elapsed
-	log.Message = fmt.Sprintf(msg, v...)
+	log.EndedAt = &end
+	log.Message = message
+
+	emit(log.writer, log)
+}
 
-	runtime.Log(log)
+// Stop is an alias of End, reading well when deferred right after starting the
+// timer. The start time is taken by Timer, so the following logs the duration of
+// the whole function:
+//
+//	defer log.Timer().Stop("Handled request")
+func (log *Log) Stop(msg string, args ...interface{}) {
+	log.End(msg, args...)
+}
+
+// Clone returns a deep copy of the log, including its attributes. Writers must
+// not retain the *Log passed to Write once it returns; a writer that needs to keep
+// it around (buffers, recorders, async queues) should keep a Clone instead.
+func (log *Log) Clone() *Log {
+	clone := *log
+
+	if log.Attrs != nil {
+		attrs := make(Attrs, len(*log.Attrs))
+		for key, val := range *log.Attrs {
+			attrs[key] = val
+		}
+		clone.Attrs = &attrs
+	}
+
+	return &clone
 }
 
 // SplitAttrs checks if the last item passed in v is an Attrs instance,
@@ -46,6 +108,16 @@ func SplitAttrs(v []interface{}) ([]interface{}, *Attrs) {
 	return v, &attrs
 }
 
+// formatMessage applies the printf args to msg. Without args, msg is returned
+// verbatim so that a stray "%" isn't mangled.
+func formatMessage(msg string, v []interface{}) string {
+	if len(v) == 0 {
+		return msg
+	}
+
+	return fmt.Sprintf(msg, v...)
+}
+
 // Now is a shortcut for returning the current time in Unix nanoseconds.
 func Now() int64 {
 	return time.Now().UnixNano()
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimerDeferredStop(t *testing.T) {
+	logger, recorder := recordLogger("test")
+
+	sleep := 50 * time.Millisecond
+	func() {
+		defer logger.Timer().Stop("slept")
+		time.Sleep(sleep)
+	}()
+
+	if len(recorder.logs) != 1 {
+		t.Fatalf("got %d logs, want 1", len(recorder.logs))
+	}
+
+	log := recorder.logs[0]
+	elapsed := time.Duration(log.ElapsedNano)
+
+	if elapsed < sleep || elapsed > sleep+time.Second {
+		t.Errorf("elapsed = %v, want about %v", elapsed, sleep)
+	}
+
+	if log.Level != "TIMER" || log.Message != "slept" {
+		t.Errorf("got a %s log %q, want a TIMER log \"slept\"", log.Level, log.Message)
+	}
+
+	if log.Elapsed != int64(elapsed/time.Millisecond) {
+		t.Errorf("Elapsed = %dms, want %dms", log.Elapsed, elapsed/time.Millisecond)
+	}
+
+	if log.StartedAt == nil || log.EndedAt == nil || log.EndedAt.Sub(*log.StartedAt) != elapsed {
+		t.Errorf("StartedAt and EndedAt don't span the elapsed time")
+	}
+}
+
+func TestTimerSharedAcrossWriters(t *testing.T) {
+	var pretty, structured bytes.Buffer
+	all := map[string]*OutputSettings{"*": {Debug: true, Info: true, Timer: true, Warn: true, Error: true}}
+
+	logger := New("test")
+	logger.Writer = NewMultiWriter([]OutputWriter{
+		StandardWriter{OutputFormat: FormatPretty, Target: &pretty, Settings: all},
+		StandardWriter{OutputFormat: FormatJSON, Target: &structured, Settings: all},
+	}, MultiOptions{})
+
+	timer := logger.Timer()
+	time.Sleep(time.Millisecond)
+	timer.Stop("done")
+
+	var decoded Log
+	if err := json.Unmarshal(structured.Bytes(), &decoded); err != nil {
+		t.Fatalf("decoding %q: %v", structured.String(), err)
+	}
+
+	if decoded.ElapsedNano == 0 {
+		t.Fatalf("JSON output %s has no elapsed time", structured.String())
+	}
+
+	if want := fmt.Sprintf("(%v)", time.Duration(decoded.ElapsedNano)); !strings.Contains(pretty.String(), want) {
+		t.Errorf("pretty output %q doesn't show the JSON duration %s", pretty.String(), want)
+	}
+
+	if decoded.ElapsedNano != timer.ElapsedNano || decoded.EndedAt == nil || !decoded.EndedAt.Equal(*timer.EndedAt) {
+		t.Errorf("JSON output %s doesn't match the timer", structured.String())
+	}
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogfmtFormat renders the log as a single line of key=value pairs.
+func (standardWriter *StandardWriter) LogfmtFormat(log *Log) string {
+	var b strings.Builder
+
+	writeLogfmtPair(&b, "time", time.Unix(0, log.Time).Format(time.RFC3339Nano))
+	writeLogfmtPair(&b, standardWriter.levelKey(), log.Level)
+	writeLogfmtPair(&b, "package", log.Package)
+	writeLogfmtPair(&b, "msg", log.Message)
+
+	if log.Level == "TIMER" {
+		writeLogfmtPair(&b, "elapsed", time.Duration(log.ElapsedNano).String())
+	}
+
+	if log.Attrs != nil {
+		limited, truncated := standardWriter.limitAttrs(*log.Attrs)
+
+		attrs := standardWriter.attrMap(limited, false)
+		if truncated > 0 {
+			attrs[AttrsTruncatedKey] = truncated
+		}
+
+		keys := make([]string, 0, len(attrs))
+		for key := range attrs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			writeLogfmtPair(&b, key, fmt.Sprintf("%v", attrs[key]))
+		}
+	}
+
+	return b.String()
+}
+
+func writeLogfmtPair(b *strings.Builder, key, val string) {
+	if b.Len() > 0 {
+		b.WriteByte(' ')
+	}
+
+	b.WriteString(key)
+	b.WriteByte('=')
+
+	if val == "" || strings.ContainsAny(val, " =\"\t\r\n") {
+		b.WriteString(strconv.Quote(val))
+	} else {
+		b.WriteString(val)
+	}
+}
@@ -1,14 +1,41 @@
 package logger
 
 import (
+	"context"
 	"fmt"
+	"math/rand"
+	"strings"
+	"time"
 )
 
-// New returns a logger bound to the given name.
-func New(name string) *Logger {
-	return &Logger{
+// New returns a logger bound to the given name, configured by the options.
+func New(name string, options ...Option) *Logger {
+	logger := &Logger{
 		Name: name,
 	}
+
+	for _, option := range options {
+		option(logger)
+	}
+
+	register(name)
+	return logger
+}
+
+// Option configures a logger created by New.
+type Option func(*Logger)
+
+// SampledKey is the attribute marking the logs of a sampled logger.
+const SampledKey = "sampled"
+
+// Sampled makes the logger emit only the given fraction (0 to 1) of its logs,
+// e.g. for a chatty subsystem, marking those it emits with a "sampled" attribute
+// so that readers know the stream is partial. Writers doing their own sampling
+// should let logs marked this way through, so that they aren't sampled twice.
+func Sampled(rate float64) Option {
+	return func(logger *Logger) {
+		logger.sampleRate = rate
+	}
 }
 
 // Logger is the unit of the logger package, a smart, pretty-printing gate between
@@ -16,18 +43,181 @@ func New(name string) *Logger {
 type Logger struct {
 	// Name by which the logger is identified when enabling or disabling it, and by envvar.
 	Name string
+
+	// Attrs are added to every log of the logger. See With.
+	Attrs Attrs
+
+	// Writer, when set, receives the logs of this logger instead of the writers of
+	// the global runtime. It isn't initialized by the logger.
+	Writer OutputWriter
+
+	// ShowCaller adds the file:line of the call site to every log of the logger, as
+	// the "caller" attribute.
+	ShowCaller bool
+
+	// CallerSkip is the number of frames skipped to find the call site, for
+	// libraries wrapping the logger. A helper calling the logger directly is one
+	// frame: with CallerSkip set to 1, the caller of the helper is reported.
+	CallerSkip int
+
+	// Interpolate replaces "{key}" placeholders in the messages of the logger with
+	// the values of the matching attributes, which are still logged as well:
+	//
+	//	log.Info("User {user_id} logged in", logger.Attr("user_id", 42))
+	//
+	// Off by default, as messages may contain braces of their own.
+	Interpolate bool
+
+	// ShowSourcePackage sets the SourcePackage of every log of the logger to the
+	// import path of the calling package, telling apart loggers of the same name
+	// in different packages. Off by default, as it walks the stack.
+	ShowSourcePackage bool
+
+	// ShowGoroutineID adds the ID of the logging goroutine to every log of the
+	// logger, as the "goroutine" attribute, to tell apart interleaved logs. Off by
+	// default, as reading it takes a stack trace.
+	ShowGoroutineID bool
+
+	// sampleRate is the fraction of logs emitted, when set. See Sampled.
+	sampleRate float64
+}
+
+// With returns a child logger with the same name, adding attrs to every log on
+// top of the parent's attributes.
+func (logger *Logger) With(attrs Attrs) *Logger {
+	child := *logger
+	child.Attrs = mergeAttrs(mergeAttrs(nil, logger.Attrs), attrs)
+	return &child
 }
 
 func (logger *Logger) Log(level, message string, args []interface{}) {
-	v, attrs := SplitAttrs(args)
+	message, attrs := formatArgs(message, args)
+	logger.write(level, message, attrs)
+}
+
+// LogCtx is like Log, attaching the IDs carried by ctx (see ContextAttrs). Attrs
+// among the args win over them.
+func (logger *Logger) LogCtx(ctx context.Context, level, message string, args []interface{}) {
+	message, attrs := formatArgs(message, args)
+
+	if ctxAttrs := ContextAttrs(ctx); ctxAttrs != nil {
+		if attrs != nil {
+			ctxAttrs = mergeAttrs(ctxAttrs, *attrs)
+		}
+		attrs = &ctxAttrs
+	}
+
+	logger.write(level, message, attrs)
+}
+
+// Logln logs the args space-separated like fmt.Sprintln, without a format string.
+// Attrs among the args are logged as attributes.
+func (logger *Logger) Logln(level string, args []interface{}) {
+	var attrs Attrs
+	var v []interface{}
+
+	for _, arg := range args {
+		if a, ok := arg.(Attrs); ok {
+			attrs = mergeAttrs(attrs, a)
+		} else {
+			v = append(v, arg)
+		}
+	}
+
+	message := strings.TrimSuffix(fmt.Sprintln(v...), "\n")
+	if attrs == nil {
+		logger.write(level, message, nil)
+	} else {
+		logger.write(level, message, &attrs)
+	}
+}
+
+func (logger *Logger) write(level, message string, attrs *Attrs) {
+	logger.writeCode(level, "", message, attrs)
+}
+
+// writeCode is like write, setting the code of the log. See ErrorCode.
+func (logger *Logger) writeCode(level, code, message string, attrs *Attrs) {
+	if logger.sampleRate > 0 && logger.sampleRate < 1 {
+		if rand.Float64() >= logger.sampleRate {
+			return
+		}
+
+		sampled := Attrs{SampledKey: true}
+		if attrs != nil {
+			sampled = mergeAttrs(sampled, *attrs)
+		}
+		attrs = &sampled
+	}
+
+	if logger.ShowCaller {
+		withCaller := Attrs{CallerKey: caller(logger.CallerSkip)}
+		if attrs != nil {
+			withCaller = mergeAttrs(withCaller, *attrs)
+		}
+		attrs = &withCaller
+	}
+
+	if logger.ShowGoroutineID {
+		withID := Attrs{GoroutineKey: goroutineID()}
+		if attrs != nil {
+			withID = mergeAttrs(withID, *attrs)
+		}
+		attrs = &withID
+	}
 
-	runtime.Log(&Log{
+	log := &Log{
 		Package: logger.Name,
 		Level:   level,
-		Message: fmt.Sprintf(message, v...),
+		Message: message,
+		Code:    code,
 		Time:    Now(),
-		Attrs:   attrs,
-	})
+		Attrs:   logger.withAttrs(attrs),
+	}
+
+	if logger.Interpolate {
+		log.Message = interpolate(log.Message, log.Attrs)
+	}
+
+	if logger.ShowSourcePackage {
+		log.SourcePackage = sourcePackage(logger.CallerSkip)
+	}
+
+	emit(logger.Writer, log)
+}
+
+// withAttrs merges the logger's attributes with the given ones, which win.
+func (logger *Logger) withAttrs(attrs *Attrs) *Attrs {
+	if len(logger.Attrs) == 0 {
+		return attrs
+	}
+
+	merged := mergeAttrs(nil, logger.Attrs)
+	if attrs != nil {
+		merged = mergeAttrs(merged, *attrs)
+	}
+
+	return &merged
+}
+
+// LogLevel logs a message at a custom level, e.g. "AUDIT". See RegisterLevel.
+func (logger *Logger) LogLevel(level, msg string, v ...interface{}) {
+	logger.Log(strings.ToUpper(level), msg, v)
+}
+
+// Debug logs diagnostic information, hidden unless the debug level is enabled.
+func (logger *Logger) Debug(msg string, v ...interface{}) {
+	logger.Log("DEBUG", msg, v)
+}
+
+// Debugln logs its args at DEBUG, space-separated.
+func (logger *Logger) Debugln(v ...interface{}) {
+	logger.Logln("DEBUG", v)
+}
+
+// DebugCtx is like Debug, attaching the request, user and trace IDs carried by ctx.
+func (logger *Logger) DebugCtx(ctx context.Context, msg string, v ...interface{}) {
+	logger.LogCtx(ctx, "DEBUG", msg, v)
 }
 
 // Info prints log information to the screen that is informational in nature.
@@ -35,16 +225,67 @@ func (logger *Logger) Info(msg string, v ...interface{}) {
 	logger.Log("INFO", msg, v)
 }
 
+// Infoln logs its args at INFO, space-separated.
+func (logger *Logger) Infoln(v ...interface{}) {
+	logger.Logln("INFO", v)
+}
+
+// InfoCtx is like Info, attaching the request, user and trace IDs carried by ctx.
+func (logger *Logger) InfoCtx(ctx context.Context, msg string, v ...interface{}) {
+	logger.LogCtx(ctx, "INFO", msg, v)
+}
+
+// Warn logs a warning, something worth attention that isn't an error.
+func (logger *Logger) Warn(msg string, v ...interface{}) {
+	logger.Log("WARN", msg, v)
+}
+
+// Warnln logs its args at WARN, space-separated.
+func (logger *Logger) Warnln(v ...interface{}) {
+	logger.Logln("WARN", v)
+}
+
+// WarnCtx is like Warn, attaching the request, user and trace IDs carried by ctx.
+func (logger *Logger) WarnCtx(ctx context.Context, msg string, v ...interface{}) {
+	logger.LogCtx(ctx, "WARN", msg, v)
+}
+
 // Error logs an error message.
 func (logger *Logger) Error(msg string, v ...interface{}) {
 	logger.Log("ERROR", msg, v)
 }
 
+// Errorf builds an error like fmt.Errorf, including "%w" wrapping, logs its
+// message at ERROR and returns it:
+//
+//	return log.Errorf("open %s: %w", path, err)
+func (logger *Logger) Errorf(format string, v ...interface{}) error {
+	args, attrs := splitArgs(format, v)
+	err := fmt.Errorf(format, args...)
+	logger.write("ERROR", err.Error(), attrs)
+	return err
+}
+
+// Errorln logs its args at ERROR, space-separated.
+func (logger *Logger) Errorln(v ...interface{}) {
+	logger.Logln("ERROR", v)
+}
+
+// ErrorCtx is like Error, attaching the request, user and trace IDs carried by ctx.
+func (logger *Logger) ErrorCtx(ctx context.Context, msg string, v ...interface{}) {
+	logger.LogCtx(ctx, "ERROR", msg, v)
+}
+
 // Timer returns a timer sub-logger.
 func (logger *Logger) Timer() *Log {
+	start := time.Now()
+
 	return &Log{
-		Package: logger.Name,
-		Level:   "TIMER",
-		Time:    Now(),
+		Package:   logger.Name,
+		Level:     "TIMER",
+		Time:      start.UnixNano(),
+		StartedAt: &start,
+		Attrs:     logger.withAttrs(nil),
+		writer:    logger.Writer,
 	}
 }
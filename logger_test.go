@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+// recordLogger returns a logger whose logs are kept by the returned writer.
+func recordLogger(name string) (*Logger, *recordWriter) {
+	recorder := &recordWriter{}
+
+	logger := New(name)
+	logger.Writer = recorder
+
+	return logger, recorder
+}
+
+func TestLogWithoutPrintfArgs(t *testing.T) {
+	logger, recorder := recordLogger("test")
+
+	logger.Info("progress: 50% done")
+	logger.Info("progress: 50% done", Attrs{"job": 1})
+
+	if len(recorder.logs) != 2 {
+		t.Fatalf("got %d logs, want 2", len(recorder.logs))
+	}
+
+	for _, log := range recorder.logs {
+		if log.Message != "progress: 50% done" {
+			t.Errorf("message = %q, want it unchanged", log.Message)
+		}
+
+		if output := (&StandardWriter{}).JSONFormat(log); !strings.Contains(output, `"progress: 50% done"`) {
+			t.Errorf("output %s doesn't contain the message unchanged", output)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Package loggerhttp logs HTTP requests through a logger.
+package loggerhttp
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/STRUCTiX/logger"
+)
+
+// Middleware logs every request once it's handled, with its method, path, status
+// and response size as attributes, and its duration as the elapsed time. 5xx
+// responses are logged as ERROR, 4xx as WARN and the rest as INFO. Handlers can
+// get a child logger carrying the method, path and request ID from the request
+// context with logger.LoggerFromContext.
+func Middleware(l *logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attrs := logger.Attrs{
+				"method": r.Method,
+				"path":   r.URL.Path,
+			}
+
+			if id := logger.RequestIDFromContext(r.Context()); id != "" {
+				attrs[logger.RequestIDKey] = id
+			}
+
+			child := l.With(attrs)
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+
+			timer := child.Timer()
+			next.ServeHTTP(recorder, r.WithContext(logger.ContextWithLogger(r.Context(), child)))
+
+			timer.Level = level(recorder.status)
+			timer.End("%s %s", r.Method, r.URL.Path, logger.Attrs{
+				"status": recorder.status,
+				"bytes":  recorder.bytes,
+			})
+		})
+	}
+}
+
+func level(status int) string {
+	switch {
+	case status >= 500:
+		return "ERROR"
+	case status >= 400:
+		return "WARN"
+	}
+
+	return "INFO"
+}
+
+// responseRecorder captures the status and size of a response.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (recorder *responseRecorder) WriteHeader(status int) {
+	if !recorder.wroteHeader {
+		recorder.status = status
+		recorder.wroteHeader = true
+	}
+
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *responseRecorder) Write(b []byte) (int, error) {
+	recorder.wroteHeader = true
+
+	n, err := recorder.ResponseWriter.Write(b)
+	recorder.bytes += n
+	return n, err
+}
+
+// Flush lets streaming handlers flush through the recorder.
+func (recorder *responseRecorder) Flush() {
+	if flusher, ok := recorder.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets WebSocket and other upgrade handlers take over the connection.
+func (recorder *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := recorder.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("loggerhttp: the response writer doesn't support hijacking")
+	}
+
+	recorder.wroteHeader = true
+	recorder.status = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+// Unwrap returns the wrapped response writer, for http.ResponseController.
+func (recorder *responseRecorder) Unwrap() http.ResponseWriter {
+	return recorder.ResponseWriter
+}
@@ -0,0 +1,110 @@
+package loggerhttp
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/STRUCTiX/logger"
+)
+
+type recorder struct {
+	logs []*logger.Log
+}
+
+func (recorder *recorder) Init() {}
+
+func (recorder *recorder) Write(log *logger.Log) {
+	recorder.logs = append(recorder.logs, log.Clone())
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		status int
+		level  string
+	}{
+		{http.StatusOK, "INFO"},
+		{http.StatusNotFound, "WARN"},
+		{http.StatusInternalServerError, "ERROR"},
+	}
+
+	for _, test := range tests {
+		recorder := &recorder{}
+		l := logger.New("http")
+		l.Writer = recorder
+
+		handler := Middleware(l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(10 * time.Millisecond)
+			w.WriteHeader(test.status)
+			w.Write([]byte("body"))
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/items", nil))
+
+		if len(recorder.logs) != 1 {
+			t.Fatalf("got %d logs, want 1", len(recorder.logs))
+		}
+
+		log := recorder.logs[0]
+		attrs := *log.Attrs
+
+		if log.Level != test.level {
+			t.Errorf("status %d: level = %s, want %s", test.status, log.Level, test.level)
+		}
+
+		if log.ElapsedNano < int64(10*time.Millisecond) {
+			t.Errorf("status %d: elapsed = %v, want at least 10ms", test.status, time.Duration(log.ElapsedNano))
+		}
+
+		if attrs["status"] != test.status || attrs["bytes"] != 4 {
+			t.Errorf("status %d: attrs = %v", test.status, attrs)
+		}
+
+		if _, ok := attrs["duration"]; ok {
+			t.Errorf("status %d: the duration is logged twice", test.status)
+		}
+	}
+}
+
+// hijackableRecorder is a ResponseRecorder supporting Hijack.
+type hijackableRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (recorder *hijackableRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	recorder.hijacked = true
+	return nil, nil, nil
+}
+
+func TestMiddlewareHijack(t *testing.T) {
+	l := logger.New("http")
+	l.Writer = &recorder{}
+
+	var hijackErr error
+	handler := Middleware(l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			t.Fatal("the response writer isn't a Hijacker")
+		}
+		_, _, hijackErr = hijacker.Hijack()
+
+		if unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter }); !ok || unwrapper.Unwrap() == nil {
+			t.Error("the response writer can't be unwrapped")
+		}
+	}))
+
+	w := &hijackableRecorder{ResponseRecorder: httptest.NewRecorder()}
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/ws", nil))
+
+	if hijackErr != nil || !w.hijacked {
+		t.Errorf("Hijack wasn't passed through: %v", hijackErr)
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ws", nil))
+	if hijackErr == nil {
+		t.Error("Hijack of a writer that doesn't support it didn't fail")
+	}
+}
@@ -0,0 +1,60 @@
+// Package loggertest routes logs into the output of Go tests.
+package loggertest
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/STRUCTiX/logger"
+)
+
+// Writer formats logs and writes them with t.Log, so that they show up next to
+// the test that emitted them, and only when it fails or runs verbosely.
+type Writer struct {
+	t         testing.TB
+	formatter *logger.StandardWriter
+	mutex     sync.RWMutex
+	done      bool
+}
+
+// NewTestWriter returns a writer logging through t. It stops writing once the
+// test has finished, as t.Log panics afterwards.
+func NewTestWriter(t testing.TB) *Writer {
+	writer := &Writer{
+		t: t,
+		formatter: &logger.StandardWriter{
+			OutputFormat: logger.FormatPretty,
+		},
+	}
+
+	t.Cleanup(func() {
+		writer.mutex.Lock()
+		writer.done = true
+		writer.mutex.Unlock()
+	})
+
+	return writer
+}
+
+func (writer *Writer) Init() {}
+
+func (writer *Writer) Write(log *logger.Log) {
+	writer.mutex.RLock()
+	defer writer.mutex.RUnlock()
+
+	if writer.done {
+		return
+	}
+
+	writer.t.Helper()
+	writer.t.Log(writer.formatter.Format(log))
+}
+
+// NewTestLogger returns a logger whose logs are written through t until the
+// test finishes. It doesn't touch the global writers, so tests using it can run
+// in parallel.
+func NewTestLogger(t testing.TB, name string) *logger.Logger {
+	l := logger.New(name)
+	l.Writer = NewTestWriter(t)
+	return l
+}
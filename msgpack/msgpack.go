@@ -0,0 +1,301 @@
+// Package msgpack provides an output writer storing logs as MessagePack maps,
+// much more compact than JSON, and a reader decoding them back for tooling.
+package msgpack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/STRUCTiX/logger"
+)
+
+// Writer encodes every log as a MessagePack map with the same keys as the JSON
+// output, prefixed with its size as a big-endian uint32.
+type Writer struct {
+	mutex sync.Mutex
+	w     io.Writer
+}
+
+// NewWriter returns a writer storing logs into w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+func (writer *Writer) Init() {}
+
+func (writer *Writer) Write(log *logger.Log) {
+	writer.TryWrite(log)
+}
+
+// TryWrite writes the log, returning the error of the underlying writer.
+func (writer *Writer) TryWrite(log *logger.Log) error {
+	encoded := Encode(log)
+
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(encoded)))
+
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+
+	if _, err := writer.w.Write(size[:]); err != nil {
+		return err
+	}
+
+	_, err := writer.w.Write(encoded)
+	return err
+}
+
+// Encode serializes the log as a MessagePack map. The start and end times of
+// timers are stored as MessagePack timestamps. Attribute values that have no
+// MessagePack equivalent are stored as strings: times in RFC 3339, durations,
+// errors and Stringers by their text, anything else with "%v".
+func Encode(log *logger.Log) []byte {
+	var buf bytes.Buffer
+
+	fields := 7
+	for _, set := range []bool{log.Code != "", log.Attrs != nil, log.StartedAt != nil, log.EndedAt != nil, log.SourcePackage != ""} {
+		if set {
+			fields++
+		}
+	}
+	writeMapHeader(&buf, fields)
+
+	writeString(&buf, "package")
+	writeString(&buf, log.Package)
+	writeString(&buf, "level")
+	writeString(&buf, log.Level)
+	writeString(&buf, "msg")
+	writeString(&buf, log.Message)
+
+	if log.Code != "" {
+		writeString(&buf, "code")
+		writeString(&buf, log.Code)
+	}
+
+	if log.Attrs != nil {
+		writeString(&buf, "attrs")
+		writeValue(&buf, map[string]interface{}(*log.Attrs))
+	}
+
+	writeString(&buf, "time")
+	writeInt(&buf, log.Time)
+	writeString(&buf, "elapsed")
+	writeInt(&buf, log.Elapsed)
+	writeString(&buf, "elapsed_nano")
+	writeInt(&buf, log.ElapsedNano)
+	writeString(&buf, "seq")
+	writeUint(&buf, log.Seq)
+
+	if log.StartedAt != nil {
+		writeString(&buf, "started_at")
+		writeTimestamp(&buf, *log.StartedAt)
+	}
+
+	if log.EndedAt != nil {
+		writeString(&buf, "ended_at")
+		writeTimestamp(&buf, *log.EndedAt)
+	}
+
+	if log.SourcePackage != "" {
+		writeString(&buf, "source_pkg")
+		writeString(&buf, log.SourcePackage)
+	}
+
+	return buf.Bytes()
+}
+
+func writeValue(buf *bytes.Buffer, val interface{}) {
+	switch v := val.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if v {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case int:
+		writeInt(buf, int64(v))
+	case int8:
+		writeInt(buf, int64(v))
+	case int16:
+		writeInt(buf, int64(v))
+	case int32:
+		writeInt(buf, int64(v))
+	case int64:
+		writeInt(buf, v)
+	case uint:
+		writeUint(buf, uint64(v))
+	case uint8:
+		writeUint(buf, uint64(v))
+	case uint16:
+		writeUint(buf, uint64(v))
+	case uint32:
+		writeUint(buf, uint64(v))
+	case uint64:
+		writeUint(buf, v)
+	case float32:
+		writeFloat(buf, float64(v))
+	case float64:
+		writeFloat(buf, v)
+	case string:
+		writeString(buf, v)
+	case []byte:
+		writeBinary(buf, v)
+	case time.Time:
+		writeString(buf, v.Format(time.RFC3339Nano))
+	case time.Duration:
+		writeString(buf, v.String())
+	case error:
+		writeString(buf, v.Error())
+	case logger.Attrs:
+		writeValue(buf, map[string]interface{}(v))
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		writeMapHeader(buf, len(v))
+		for _, key := range keys {
+			writeString(buf, key)
+			writeValue(buf, v[key])
+		}
+	case []interface{}:
+		writeArrayHeader(buf, len(v))
+		for _, item := range v {
+			writeValue(buf, item)
+		}
+	case fmt.Stringer:
+		writeString(buf, v.String())
+	default:
+		writeString(buf, fmt.Sprintf("%v", v))
+	}
+}
+
+func writeInt(buf *bytes.Buffer, v int64) {
+	if v >= 0 {
+		writeUint(buf, uint64(v))
+		return
+	}
+
+	switch {
+	case v >= -32:
+		buf.WriteByte(byte(v))
+	case v >= math.MinInt8:
+		buf.WriteByte(0xd0)
+		buf.WriteByte(byte(v))
+	case v >= math.MinInt16:
+		buf.WriteByte(0xd1)
+		binary.Write(buf, binary.BigEndian, int16(v))
+	case v >= math.MinInt32:
+		buf.WriteByte(0xd2)
+		binary.Write(buf, binary.BigEndian, int32(v))
+	default:
+		buf.WriteByte(0xd3)
+		binary.Write(buf, binary.BigEndian, v)
+	}
+}
+
+func writeUint(buf *bytes.Buffer, v uint64) {
+	switch {
+	case v <= 0x7f:
+		buf.WriteByte(byte(v))
+	case v <= math.MaxUint8:
+		buf.WriteByte(0xcc)
+		buf.WriteByte(byte(v))
+	case v <= math.MaxUint16:
+		buf.WriteByte(0xcd)
+		binary.Write(buf, binary.BigEndian, uint16(v))
+	case v <= math.MaxUint32:
+		buf.WriteByte(0xce)
+		binary.Write(buf, binary.BigEndian, uint32(v))
+	default:
+		buf.WriteByte(0xcf)
+		binary.Write(buf, binary.BigEndian, v)
+	}
+}
+
+func writeFloat(buf *bytes.Buffer, v float64) {
+	buf.WriteByte(0xcb)
+	binary.Write(buf, binary.BigEndian, math.Float64bits(v))
+}
+
+// timestampType is the MessagePack extension type of timestamps.
+const timestampType = 0xff
+
+// writeTimestamp writes t in the 96-bit timestamp format, which holds any time.
+func writeTimestamp(buf *bytes.Buffer, t time.Time) {
+	buf.WriteByte(0xc7)
+	buf.WriteByte(12)
+	buf.WriteByte(timestampType)
+	binary.Write(buf, binary.BigEndian, uint32(t.Nanosecond()))
+	binary.Write(buf, binary.BigEndian, t.Unix())
+}
+
+func writeString(buf *bytes.Buffer, s string) {
+	switch n := len(s); {
+	case n <= 31:
+		buf.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		buf.WriteByte(0xd9)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xda)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xdb)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+
+	buf.WriteString(s)
+}
+
+func writeBinary(buf *bytes.Buffer, b []byte) {
+	switch n := len(b); {
+	case n <= math.MaxUint8:
+		buf.WriteByte(0xc4)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xc5)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xc6)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+
+	buf.Write(b)
+}
+
+func writeArrayHeader(buf *bytes.Buffer, n int) {
+	switch {
+	case n <= 15:
+		buf.WriteByte(0x90 | byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xdc)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xdd)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
+
+func writeMapHeader(buf *bytes.Buffer, n int) {
+	switch {
+	case n <= 15:
+		buf.WriteByte(0x80 | byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xde)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xdf)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
@@ -0,0 +1,73 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/STRUCTiX/logger"
+)
+
+func TestRoundTrip(t *testing.T) {
+	started := time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.UTC)
+	ended := started.Add(1500 * time.Millisecond)
+
+	want := &logger.Log{
+		Package:       "db",
+		Level:         "TIMER",
+		Message:       "query",
+		Code:          "DB001",
+		Attrs:         &logger.Attrs{"rows": int64(3), "table": "users", "ok": true},
+		Time:          started.UnixNano(),
+		Elapsed:       1500,
+		ElapsedNano:   int64(1500 * time.Millisecond),
+		Seq:           42,
+		StartedAt:     &started,
+		EndedAt:       &ended,
+		SourcePackage: "example.com/app/db",
+	}
+
+	var buf bytes.Buffer
+	if err := NewWriter(&buf).TryWrite(want); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := NewReader(&buf).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !got.StartedAt.Equal(*want.StartedAt) || !got.EndedAt.Equal(*want.EndedAt) {
+		t.Errorf("times = %v, %v, want %v, %v", got.StartedAt, got.EndedAt, want.StartedAt, want.EndedAt)
+	}
+
+	got.StartedAt, got.EndedAt = want.StartedAt, want.EndedAt
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decoded %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeTimestamps(t *testing.T) {
+	for _, encoded := range [][]byte{
+		{0xd6, 0xff, 0, 0, 0, 10},
+		{0xd7, 0xff, 0, 0, 0, 20, 0, 0, 0, 10},
+		{0xc7, 12, 0xff, 0, 0, 0, 5, 0, 0, 0, 0, 0, 0, 0, 10},
+	} {
+		decoder := &decoder{r: bytes.NewReader(encoded)}
+		timestamp, ok := decoder.value().(time.Time)
+		if !ok || decoder.err != nil || timestamp.Unix() != 10 {
+			t.Errorf("decoding % x = %v, %v, want a time 10s after the epoch", encoded, timestamp, decoder.err)
+		}
+	}
+}
+
+func TestReadRejectsOversizedLogs(t *testing.T) {
+	var prefix [4]byte
+	binary.BigEndian.PutUint32(prefix[:], MaxLogSize+1)
+
+	if _, err := NewReader(bytes.NewReader(prefix[:])).Read(); err != ErrInvalid {
+		t.Errorf("Read() error = %v, want ErrInvalid", err)
+	}
+}
@@ -0,0 +1,258 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"time"
+
+	"github.com/STRUCTiX/logger"
+)
+
+// ErrInvalid is returned when decoding data that isn't a log written by Writer.
+var ErrInvalid = errors.New("msgpack: invalid log")
+
+// MaxLogSize bounds the size of the logs read by a Reader, so that a corrupt size
+// prefix doesn't make it allocate gigabytes.
+const MaxLogSize = 16 << 20
+
+// Reader decodes the logs stored by a Writer.
+type Reader struct {
+	r io.Reader
+}
+
+// NewReader returns a reader decoding logs from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: r}
+}
+
+// Read returns the next log, or io.EOF once there are no more. Integer
+// attributes are decoded as int64, or uint64 for values above math.MaxInt64. Logs
+// larger than MaxLogSize are rejected with ErrInvalid.
+func (reader *Reader) Read() (*logger.Log, error) {
+	var prefix [4]byte
+	if _, err := io.ReadFull(reader.r, prefix[:]); err != nil {
+		return nil, err
+	}
+
+	size := binary.BigEndian.Uint32(prefix[:])
+	if size > MaxLogSize {
+		return nil, ErrInvalid
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(reader.r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	return Decode(data)
+}
+
+// Decode deserializes a log encoded by Encode.
+func Decode(data []byte) (*logger.Log, error) {
+	decoder := &decoder{r: bytes.NewReader(data)}
+
+	fields, ok := decoder.value().(map[string]interface{})
+	if !ok || decoder.err != nil {
+		return nil, ErrInvalid
+	}
+
+	log := &logger.Log{}
+	log.Package, _ = fields["package"].(string)
+	log.Level, _ = fields["level"].(string)
+	log.Message, _ = fields["msg"].(string)
+	log.Code, _ = fields["code"].(string)
+	log.SourcePackage, _ = fields["source_pkg"].(string)
+	log.Time = toInt(fields["time"])
+	log.Elapsed = toInt(fields["elapsed"])
+	log.ElapsedNano = toInt(fields["elapsed_nano"])
+	log.Seq = uint64(toInt(fields["seq"]))
+
+	if startedAt, ok := fields["started_at"].(time.Time); ok {
+		log.StartedAt = &startedAt
+	}
+
+	if endedAt, ok := fields["ended_at"].(time.Time); ok {
+		log.EndedAt = &endedAt
+	}
+
+	if attrs, ok := fields["attrs"].(map[string]interface{}); ok {
+		decoded := logger.Attrs(attrs)
+		log.Attrs = &decoded
+	}
+
+	return log, nil
+}
+
+func toInt(val interface{}) int64 {
+	switch v := val.(type) {
+	case int64:
+		return v
+	case uint64:
+		return int64(v)
+	}
+
+	return 0
+}
+
+type decoder struct {
+	r   *bytes.Reader
+	err error
+}
+
+func (decoder *decoder) value() interface{} {
+	b := decoder.byte()
+	if decoder.err != nil {
+		return nil
+	}
+
+	switch {
+	case b <= 0x7f:
+		return int64(b)
+	case b >= 0xe0:
+		return int64(int8(b))
+	case b&0xe0 == 0xa0:
+		return decoder.string(int(b & 0x1f))
+	case b&0xf0 == 0x90:
+		return decoder.array(int(b & 0x0f))
+	case b&0xf0 == 0x80:
+		return decoder.object(int(b & 0x0f))
+	}
+
+	switch b {
+	case 0xc0:
+		return nil
+	case 0xc2:
+		return false
+	case 0xc3:
+		return true
+	case 0xc4, 0xc5, 0xc6:
+		return []byte(decoder.string(decoder.length(b - 0xc4)))
+	case 0xd6:
+		return decoder.timestamp(4)
+	case 0xd7:
+		return decoder.timestamp(8)
+	case 0xc7:
+		return decoder.timestamp(decoder.length(0))
+	case 0xcb:
+		return math.Float64frombits(decoder.uint(8))
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		v := decoder.uint(1 << (b - 0xcc))
+		if v > math.MaxInt64 {
+			return v
+		}
+		return int64(v)
+	case 0xd0:
+		return int64(int8(decoder.uint(1)))
+	case 0xd1:
+		return int64(int16(decoder.uint(2)))
+	case 0xd2:
+		return int64(int32(decoder.uint(4)))
+	case 0xd3:
+		return int64(decoder.uint(8))
+	case 0xd9, 0xda, 0xdb:
+		return decoder.string(decoder.length(b - 0xd9))
+	case 0xdc, 0xdd:
+		return decoder.array(decoder.length(b - 0xdc + 1))
+	case 0xde, 0xdf:
+		return decoder.object(decoder.length(b - 0xde + 1))
+	}
+
+	decoder.err = ErrInvalid
+	return nil
+}
+
+// length reads a length of 1, 2 or 4 bytes, for sizes 0, 1 and 2.
+func (decoder *decoder) length(size byte) int {
+	return int(decoder.uint(1 << size))
+}
+
+func (decoder *decoder) uint(n int) uint64 {
+	var v uint64
+	for i := 0; i < n; i++ {
+		v = v<<8 | uint64(decoder.byte())
+	}
+
+	return v
+}
+
+func (decoder *decoder) byte() byte {
+	b, err := decoder.r.ReadByte()
+	if err != nil && decoder.err == nil {
+		decoder.err = ErrInvalid
+	}
+
+	return b
+}
+
+// timestamp reads the type and data of an extension of n bytes, which must be a
+// timestamp in the 32, 64 or 96-bit format.
+func (decoder *decoder) timestamp(n int) interface{} {
+	if decoder.byte() != timestampType {
+		decoder.err = ErrInvalid
+		return nil
+	}
+
+	switch n {
+	case 4:
+		return time.Unix(int64(decoder.uint(4)), 0)
+	case 8:
+		v := decoder.uint(8)
+		return time.Unix(int64(v&(1<<34-1)), int64(v>>34))
+	case 12:
+		nsec := decoder.uint(4)
+		return time.Unix(int64(decoder.uint(8)), int64(nsec))
+	}
+
+	decoder.err = ErrInvalid
+	return nil
+}
+
+func (decoder *decoder) string(n int) string {
+	if n > decoder.r.Len() {
+		decoder.err = ErrInvalid
+		return ""
+	}
+
+	b := make([]byte, n)
+	decoder.r.Read(b)
+	return string(b)
+}
+
+func (decoder *decoder) array(n int) []interface{} {
+	if n > decoder.r.Len() {
+		decoder.err = ErrInvalid
+		return nil
+	}
+
+	result := make([]interface{}, 0, n)
+	for i := 0; i < n && decoder.err == nil; i++ {
+		result = append(result, decoder.value())
+	}
+
+	return result
+}
+
+func (decoder *decoder) object(n int) map[string]interface{} {
+	if n > decoder.r.Len() {
+		decoder.err = ErrInvalid
+		return nil
+	}
+
+	result := make(map[string]interface{}, n)
+	for i := 0; i < n && decoder.err == nil; i++ {
+		key, ok := decoder.value().(string)
+		if !ok {
+			decoder.err = ErrInvalid
+			return nil
+		}
+		result[key] = decoder.value()
+	}
+
+	return result
+}
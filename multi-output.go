@@ -0,0 +1,140 @@
+package logger
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// TryWriter is implemented by writers that can report a failed write, e.g. when
+// their destination is unreachable.
+type TryWriter interface {
+	TryWrite(log *Log) error
+}
+
+// MultiOptions configures a MultiWriter.
+type MultiOptions struct {
+	// OnChildError receives the errors and panics of the children's writes.
+	OnChildError func(child OutputWriter, err error)
+
+	// FailureThreshold is the number of consecutive failures after which a child
+	// is skipped for RetryAfter. Zero never skips children.
+	FailureThreshold int
+
+	// RetryAfter is how long a failing child is skipped before being tried
+	// again. Defaults to 30 seconds.
+	RetryAfter time.Duration
+}
+
+// MultiWriter fans out logs to several writers, isolating them from each other: a
+// child that fails or panics doesn't keep the others from getting the log, and a
+// child failing persistently can be skipped for a while. Children that may block
+// should be wrapped in a TimeoutWriter.
+type MultiWriter struct {
+	children []*multiChild
+	opts     MultiOptions
+}
+
+type multiChild struct {
+	writer    OutputWriter
+	failures  int64
+	skipUntil int64
+}
+
+// NewMultiWriter returns a writer sending every log to each of writers.
+func NewMultiWriter(writers []OutputWriter, opts MultiOptions) *MultiWriter {
+	if opts.RetryAfter <= 0 {
+		opts.RetryAfter = 30 * time.Second
+	}
+
+	multiWriter := &MultiWriter{opts: opts}
+	for _, writer := range writers {
+		multiWriter.children = append(multiWriter.children, &multiChild{writer: writer})
+	}
+
+	return multiWriter
+}
+
+func (multiWriter *MultiWriter) Init() {
+	for _, child := range multiWriter.children {
+		child.writer.Init()
+	}
+}
+
+func (multiWriter *MultiWriter) Write(log *Log) {
+	now := Now()
+
+	for _, child := range multiWriter.children {
+		if atomic.LoadInt64(&child.skipUntil) > now {
+			continue
+		}
+
+		if err := writeChild(child.writer, log); err != nil {
+			multiWriter.fail(child, err, now)
+			continue
+		}
+
+		atomic.StoreInt64(&child.failures, 0)
+	}
+}
+
+func (multiWriter *MultiWriter) fail(child *multiChild, err error, now int64) {
+	if multiWriter.opts.OnChildError != nil {
+		multiWriter.opts.OnChildError(child.writer, err)
+	}
+
+	threshold := int64(multiWriter.opts.FailureThreshold)
+	if threshold <= 0 {
+		return
+	}
+
+	if atomic.AddInt64(&child.failures, 1) >= threshold {
+		atomic.StoreInt64(&child.skipUntil, now+int64(multiWriter.opts.RetryAfter))
+		// Once retried, a single failure skips the child again.
+		atomic.StoreInt64(&child.failures, threshold-1)
+	}
+}
+
+// Skipped returns the children currently skipped because they kept failing.
+func (multiWriter *MultiWriter) Skipped() []OutputWriter {
+	now := Now()
+
+	var skipped []OutputWriter
+	for _, child := range multiWriter.children {
+		if atomic.LoadInt64(&child.skipUntil) > now {
+			skipped = append(skipped, child.writer)
+		}
+	}
+
+	return skipped
+}
+
+// Flush flushes the children implementing Flusher, returning the first error.
+func (multiWriter *MultiWriter) Flush() error {
+	var first error
+	for _, child := range multiWriter.children {
+		if flusher, ok := child.writer.(Flusher); ok {
+			if err := flusher.Flush(); err != nil && first == nil {
+				first = err
+			}
+		}
+	}
+
+	return first
+}
+
+// writeChild writes the log to writer, turning a panic into an error.
+func writeChild(writer OutputWriter, log *Log) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("logger: writer panicked: %v", r)
+		}
+	}()
+
+	if tryWriter, ok := writer.(TryWriter); ok {
+		return tryWriter.TryWrite(log)
+	}
+
+	writer.Write(log)
+	return nil
+}
@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// failWriter fails its writes, with an error or a panic, while failing is set,
+// and keeps the messages of the others.
+type failWriter struct {
+	failing  bool
+	panics   bool
+	writes   int
+	messages []string
+}
+
+func (failWriter *failWriter) Init() {}
+
+func (failWriter *failWriter) Write(log *Log) {
+	failWriter.TryWrite(log)
+}
+
+func (failWriter *failWriter) TryWrite(log *Log) error {
+	failWriter.writes++
+
+	if !failWriter.failing {
+		failWriter.messages = append(failWriter.messages, log.Message)
+		return nil
+	}
+
+	if failWriter.panics {
+		panic("broken")
+	}
+
+	return errors.New("unreachable")
+}
+
+func TestMultiWriterIsolation(t *testing.T) {
+	tests := []struct {
+		name  string
+		child *failWriter
+		error string
+	}{
+		{"Error", &failWriter{failing: true}, "unreachable"},
+		{"Panic", &failWriter{failing: true, panics: true}, "logger: writer panicked: broken"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			before, after := &recordWriter{}, &recordWriter{}
+
+			var errs []error
+			multiWriter := NewMultiWriter([]OutputWriter{before, test.child, after}, MultiOptions{
+				OnChildError: func(child OutputWriter, err error) {
+					if child != test.child {
+						t.Errorf("OnChildError got %v, want the failing child", child)
+					}
+					errs = append(errs, err)
+				},
+			})
+
+			for i := 0; i < 3; i++ {
+				multiWriter.Write(&Log{Message: "fan out"})
+			}
+
+			if len(before.logs) != 3 || len(after.logs) != 3 {
+				t.Errorf("the healthy children got %d and %d logs, want 3 each", len(before.logs), len(after.logs))
+			}
+
+			if len(errs) != 3 || errs[0].Error() != test.error {
+				t.Errorf("OnChildError got %v, want 3 times %q", errs, test.error)
+			}
+
+			if skipped := multiWriter.Skipped(); len(skipped) != 0 {
+				t.Errorf("skipped %v without a FailureThreshold", skipped)
+			}
+		})
+	}
+}
+
+func TestMultiWriterSkipsFailingChild(t *testing.T) {
+	child := &failWriter{failing: true}
+	healthy := &recordWriter{}
+	multiWriter := NewMultiWriter([]OutputWriter{child, healthy}, MultiOptions{
+		FailureThreshold: 2,
+		RetryAfter:       50 * time.Millisecond,
+	})
+
+	steps := []struct {
+		failing bool
+		sleep   time.Duration
+		writes  int
+		skipped bool
+	}{
+		{true, 0, 1, false},
+		{true, 0, 2, true},
+		{true, 0, 2, true},
+		// Retried after RetryAfter, a single failure skips it again.
+		{true, 60 * time.Millisecond, 3, true},
+		{false, 60 * time.Millisecond, 4, false},
+		{true, 0, 5, false},
+	}
+
+	for i, step := range steps {
+		time.Sleep(step.sleep)
+		child.failing = step.failing
+		multiWriter.Write(&Log{Message: "fan out"})
+
+		if child.writes != step.writes {
+			t.Errorf("step %d: the child got %d writes, want %d", i, child.writes, step.writes)
+		}
+
+		if skipped := len(multiWriter.Skipped()) == 1; skipped != step.skipped {
+			t.Errorf("step %d: skipped = %v, want %v", i, skipped, step.skipped)
+		}
+	}
+
+	if len(healthy.logs) != len(steps) {
+		t.Errorf("the healthy child got %d logs, want %d", len(healthy.logs), len(steps))
+	}
+}
@@ -0,0 +1,24 @@
+package logger
+
+// NullWriter is an OutputWriter that discards every log. See Disable.
+type NullWriter struct{}
+
+func (nullWriter NullWriter) Init() {}
+
+func (nullWriter NullWriter) Write(log *Log) {}
+
+func (nullWriter NullWriter) Flush() error {
+	return nil
+}
+
+func (nullWriter NullWriter) Close() error {
+	return nil
+}
+
+// Disable replaces all writers with a NullWriter, turning logging off entirely
+// regardless of the LOG setting. Useful for benchmarks and tests.
+func Disable() {
+	runtime.setWriters(func([]OutputWriter) []OutputWriter {
+		return []OutputWriter{NullWriter{}}
+	})
+}
@@ -0,0 +1,29 @@
+package logger
+
+import "sync"
+
+// onceKeys holds the keys of the logs made with InfoOnce and WarnOnce, across all
+// loggers.
+var onceKeys sync.Map
+
+// firstTime reports whether key is seen for the first time.
+func firstTime(key string) bool {
+	_, seen := onceKeys.LoadOrStore(key, struct{}{})
+	return !seen
+}
+
+// InfoOnce is like Info, logging only the first time key is seen in the process,
+// e.g. for startup notices reached on every request.
+func (logger *Logger) InfoOnce(key, msg string, v ...interface{}) {
+	if firstTime(key) {
+		logger.Log("INFO", msg, v)
+	}
+}
+
+// WarnOnce is like Warn, logging only the first time key is seen in the process,
+// e.g. for deprecation warnings.
+func (logger *Logger) WarnOnce(key, msg string, v ...interface{}) {
+	if firstTime(key) {
+		logger.Log("WARN", msg, v)
+	}
+}
@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"io"
+	"os"
+)
+
+// Pipeline assembles several outputs, each with its own target, format and
+// levels, and installs them as the writers of the runtime in one go:
+//
+//	err := logger.NewPipeline().
+//		Output(os.Stderr, logger.FormatPretty, "*@info").
+//		File("debug.log", logger.FormatJSON, "*@debug").
+//		Writer(alerts).
+//		Install()
+//
+// Close the pipeline before exiting to persist buffered output.
+type Pipeline struct {
+	writers []OutputWriter
+	err     error
+}
+
+// NewPipeline returns an empty pipeline.
+func NewPipeline() *Pipeline {
+	return &Pipeline{}
+}
+
+// Output adds a standard output writing to file in the given format, showing the
+// packages and levels selected by spec, which follows the LOG envvar syntax.
+func (pipeline *Pipeline) Output(file *os.File, format, spec string) *Pipeline {
+	writer := NewStandardOutput(file).(StandardWriter)
+	writer.OutputFormat = format
+	writer.Configure(spec)
+
+	return pipeline.Writer(writer)
+}
+
+// File adds a FileWriter appending to the file at path in the given format,
+// showing the packages and levels selected by spec.
+func (pipeline *Pipeline) File(path, format, spec string) *Pipeline {
+	writer, err := NewFileWriter(path, FileOptions{})
+	if err != nil {
+		if pipeline.err == nil {
+			pipeline.err = err
+		}
+		return pipeline
+	}
+
+	writer.Formatter.OutputFormat = format
+	writer.Formatter.Configure(spec)
+
+	return pipeline.Writer(writer)
+}
+
+// Writer adds an arbitrary writer.
+func (pipeline *Pipeline) Writer(writer OutputWriter) *Pipeline {
+	pipeline.writers = append(pipeline.writers, writer)
+	return pipeline
+}
+
+// Writers returns the writers of the pipeline.
+func (pipeline *Pipeline) Writers() []OutputWriter {
+	return pipeline.writers
+}
+
+// Install initializes the writers and makes them replace the current writers of
+// the runtime. Nothing is installed if an output couldn't be created.
+func (pipeline *Pipeline) Install() error {
+	if pipeline.err != nil {
+		return pipeline.err
+	}
+
+	for _, writer := range pipeline.writers {
+		writer.Init()
+	}
+
+	writers := append([]OutputWriter(nil), pipeline.writers...)
+	runtime.setWriters(func([]OutputWriter) []OutputWriter {
+		return writers
+	})
+
+	return nil
+}
+
+// Close flushes and closes the writers of the pipeline that support it, e.g. to
+// persist buffered file output before the program exits.
+func (pipeline *Pipeline) Close() error {
+	var first error
+
+	for _, writer := range pipeline.writers {
+		var err error
+		if closer, ok := writer.(io.Closer); ok {
+			err = closer.Close()
+		} else if flusher, ok := writer.(Flusher); ok {
+			err = flusher.Flush()
+		}
+
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
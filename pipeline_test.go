@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// tempDir returns a temporary directory removed once the test ends.
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "logger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	return dir
+}
+
+// keepWriters restores the writers of the runtime once the test ends.
+func keepWriters(t *testing.T) {
+	previous := runtime.writers()
+	t.Cleanup(func() {
+		runtime.setWriters(func([]OutputWriter) []OutputWriter {
+			return previous
+		})
+	})
+}
+
+func TestPipeline(t *testing.T) {
+	keepWriters(t)
+	dir := tempDir(t)
+
+	stderr, err := os.Create(filepath.Join(dir, "stderr.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stderr.Close()
+
+	recorder := &recordWriter{}
+	pipeline := NewPipeline().
+		Output(stderr, FormatJSON, "*@error").
+		File(filepath.Join(dir, "debug.log"), FormatLogfmt, "*@debug").
+		Writer(recorder)
+
+	if err := pipeline.Install(); err != nil {
+		t.Fatal(err)
+	}
+
+	runtime.Log(&Log{Package: "db", Level: "DEBUG", Message: "connected"})
+	runtime.Log(&Log{Package: "db", Level: "ERROR", Message: "failed"})
+
+	if err := pipeline.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{"Output", readFile(t, stderr.Name()), []string{`"msg":"failed"`}},
+		{"File", readFile(t, filepath.Join(dir, "debug.log")), []string{"msg=connected", "msg=failed"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lines := strings.Split(strings.TrimSpace(test.output), "\n")
+			if len(lines) != len(test.want) {
+				t.Fatalf("wrote %q, want %d lines", test.output, len(test.want))
+			}
+
+			for i, line := range lines {
+				if !strings.Contains(line, test.want[i]) {
+					t.Errorf("line %d is %q, want it to contain %q", i, line, test.want[i])
+				}
+			}
+		})
+	}
+
+	if len(recorder.logs) != 2 {
+		t.Errorf("the writer got %d logs, want 2", len(recorder.logs))
+	}
+}
+
+func TestPipelineFileError(t *testing.T) {
+	keepWriters(t)
+
+	installed := &recordWriter{}
+	runtime.setWriters(func([]OutputWriter) []OutputWriter {
+		return []OutputWriter{installed}
+	})
+
+	err := NewPipeline().
+		Writer(&recordWriter{}).
+		File(filepath.Join(tempDir(t), "missing", "debug.log"), FormatJSON, "*").
+		Install()
+
+	if err == nil {
+		t.Fatal("Install() succeeded with a file in a missing directory")
+	}
+
+	if writers := runtime.writers(); len(writers) != 1 || writers[0] != installed {
+		t.Error("the writers were replaced despite the error")
+	}
+}
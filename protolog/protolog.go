@@ -0,0 +1,206 @@
+// Package protolog provides an output writer streaming logs to a gRPC collector
+// as LogEntry messages (see logentry.proto). It's a module of its own, so that
+// the logger doesn't depend on gRPC and protobuf.
+package protolog
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative logentry.proto
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/STRUCTiX/logger"
+	"google.golang.org/grpc"
+)
+
+// Defaults of Options.
+const (
+	DefaultBatchSize     = 100
+	DefaultFlushInterval = time.Second
+)
+
+// maxPendingBatches is how many batches are kept while the collector can't be
+// reached, before pending logs are dropped.
+const maxPendingBatches = 10
+
+// Options configures a Writer.
+type Options struct {
+	// BatchSize is the number of logs sent at once. Defaults to DefaultBatchSize.
+	BatchSize int
+
+	// FlushInterval bounds how long a log waits for its batch to fill up.
+	// Defaults to DefaultFlushInterval.
+	FlushInterval time.Duration
+}
+
+// Writer sends logs in batches over a Collector.Stream client stream. The stream
+// is opened lazily and again after a failed send, keeping the logs not sent yet
+// for the next attempt, which is made after FlushInterval if no other log comes.
+// Reconnecting the underlying connection is left to gRPC.
+//
+// Batches are sent by the goroutine writing the log that fills them, or calling
+// Flush, while holding the writer's lock: a collector that stops reading blocks
+// logging once gRPC's flow control window is full. Wrap the writer with
+// logger.NewAsyncWriter or logger.NewTimeoutWriter to keep it off the callers.
+type Writer struct {
+	client  CollectorClient
+	options Options
+
+	mutex   sync.Mutex
+	stream  Collector_StreamClient
+	pending []*LogEntry
+	timer   *time.Timer
+}
+
+// NewWriter returns a writer streaming to the collector reached through conn.
+func NewWriter(conn grpc.ClientConnInterface, options Options) *Writer {
+	if options.BatchSize <= 0 {
+		options.BatchSize = DefaultBatchSize
+	}
+
+	if options.FlushInterval <= 0 {
+		options.FlushInterval = DefaultFlushInterval
+	}
+
+	return &Writer{client: NewCollectorClient(conn), options: options}
+}
+
+func (writer *Writer) Init() {}
+
+func (writer *Writer) Write(log *logger.Log) {
+	writer.TryWrite(log)
+}
+
+// TryWrite queues the log, returning the error of sending the batch when it's
+// full.
+func (writer *Writer) TryWrite(log *logger.Log) error {
+	entry := NewLogEntry(log)
+
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+
+	writer.pending = append(writer.pending, entry)
+
+	if len(writer.pending) >= writer.options.BatchSize {
+		return writer.flush()
+	}
+
+	writer.arm()
+
+	return nil
+}
+
+// arm starts the timer flushing the pending logs, unless it's already running.
+func (writer *Writer) arm() {
+	if writer.timer == nil {
+		writer.timer = time.AfterFunc(writer.options.FlushInterval, func() {
+			writer.Flush()
+		})
+	}
+}
+
+// Flush sends the pending logs.
+func (writer *Writer) Flush() error {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+
+	return writer.flush()
+}
+
+// Close sends the pending logs and closes the stream, returning the collector's
+// error if it failed to receive them.
+func (writer *Writer) Close() error {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+
+	err := writer.flush()
+
+	if writer.timer != nil {
+		writer.timer.Stop()
+		writer.timer = nil
+	}
+
+	if writer.stream != nil {
+		if _, closeErr := writer.stream.CloseAndRecv(); err == nil && closeErr != nil {
+			err = fmt.Errorf("protolog: %v", closeErr)
+		}
+		writer.stream = nil
+	}
+
+	return err
+}
+
+func (writer *Writer) flush() error {
+	if writer.timer != nil {
+		writer.timer.Stop()
+		writer.timer = nil
+	}
+
+	if len(writer.pending) == 0 {
+		return nil
+	}
+
+	err := writer.send()
+	if err == nil {
+		writer.pending = writer.pending[:0]
+		return nil
+	}
+
+	if len(writer.pending) >= maxPendingBatches*writer.options.BatchSize {
+		writer.pending = nil
+	} else {
+		writer.arm()
+	}
+
+	return err
+}
+
+// send streams the pending logs, dropping the ones sent from pending. On failure
+// the stream is closed to get the actual error, since Send only reports io.EOF.
+func (writer *Writer) send() error {
+	if writer.stream == nil {
+		stream, err := writer.client.Stream(context.Background())
+		if err != nil {
+			return fmt.Errorf("protolog: %v", err)
+		}
+		writer.stream = stream
+	}
+
+	for i, entry := range writer.pending {
+		if err := writer.stream.Send(entry); err != nil {
+			writer.pending = writer.pending[i:]
+
+			if _, recvErr := writer.stream.CloseAndRecv(); recvErr != nil {
+				err = recvErr
+			}
+			writer.stream = nil
+
+			return fmt.Errorf("protolog: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// NewLogEntry converts the log to a LogEntry. Attribute values are formatted
+// with "%v".
+func NewLogEntry(log *logger.Log) *LogEntry {
+	entry := &LogEntry{
+		TimeUnixNano: log.Time,
+		Level:        log.Level,
+		Package:      log.Package,
+		Message:      log.Message,
+		ElapsedNano:  log.ElapsedNano,
+	}
+
+	if log.Attrs != nil && len(*log.Attrs) > 0 {
+		entry.Attrs = make(map[string]string, len(*log.Attrs))
+		for key, value := range *log.Attrs {
+			entry.Attrs[key] = fmt.Sprintf("%v", value)
+		}
+	}
+
+	return entry
+}
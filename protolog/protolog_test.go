@@ -0,0 +1,147 @@
+package protolog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/STRUCTiX/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type collector struct {
+	UnimplementedCollectorServer
+
+	mutex   sync.Mutex
+	entries []*LogEntry
+}
+
+func (collector *collector) Stream(stream Collector_StreamServer) error {
+	var received int64
+	for {
+		entry, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(&StreamSummary{Received: received})
+		}
+		if err != nil {
+			return err
+		}
+
+		collector.mutex.Lock()
+		collector.entries = append(collector.entries, entry)
+		collector.mutex.Unlock()
+		received++
+	}
+}
+
+func TestWriterStreamsEntries(t *testing.T) {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	collector := &collector{}
+	RegisterCollectorServer(server, collector)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	writer := NewWriter(conn, Options{BatchSize: 2})
+	for _, message := range []string{"one", "two", "three"} {
+		writer.Write(&logger.Log{
+			Time:    1,
+			Level:   "INFO",
+			Package: "protolog",
+			Message: message,
+			Attrs:   &logger.Attrs{"n": 1},
+		})
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+
+	if len(collector.entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(collector.entries))
+	}
+
+	entry := collector.entries[2]
+	if entry.Message != "three" || entry.Level != "INFO" || entry.Package != "protolog" || entry.Attrs["n"] != "1" {
+		t.Errorf("unexpected entry %v", entry)
+	}
+}
+
+func TestWriterRetriesAfterFailure(t *testing.T) {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	collector := &collector{}
+	RegisterCollectorServer(server, collector)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	var down int32 = 1
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			if atomic.LoadInt32(&down) == 1 {
+				return nil, errors.New("collector down")
+			}
+			return listener.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	writer := NewWriter(conn, Options{BatchSize: 1, FlushInterval: 10 * time.Millisecond})
+
+	if err := writer.TryWrite(&logger.Log{Level: "INFO", Package: "protolog", Message: "kept"}); err == nil {
+		t.Fatal("TryWrite() succeeded while the collector was down")
+	}
+
+	atomic.StoreInt32(&down, 0)
+
+	// No log is written anymore: only the timer can send the pending one.
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		writer.mutex.Lock()
+		pending := len(writer.pending)
+		writer.mutex.Unlock()
+
+		if pending == 0 {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("the pending log wasn't sent again once the collector was back")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+
+	if len(collector.entries) != 1 || collector.entries[0].Message != "kept" {
+		t.Errorf("got entries %v, want the one kept", collector.entries)
+	}
+}
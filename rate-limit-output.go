@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimitOptions configures a RateLimitWriter.
+type RateLimitOptions struct {
+	// Limit is the maximum number of logs per package per interval. Zero means
+	// unlimited.
+	Limit int
+
+	// Packages overrides Limit for specific packages, keyed by package name as in
+	// the LOG envvar: "db" also covers "db.pool", unless it has its own entry. A
+	// "*" entry replaces Limit as the default.
+	Packages map[string]int
+
+	// Interval is how often the counters are reset. Defaults to one second.
+	Interval time.Duration
+}
+
+// RateLimitWriter forwards logs to an inner writer, dropping the logs of a package
+// once it has exceeded its limit for the current interval.
+type RateLimitWriter struct {
+	inner   OutputWriter
+	opts    RateLimitOptions
+	mutex   sync.Mutex
+	counts  map[string]int
+	resetAt int64
+}
+
+// NewRateLimitWriter wraps inner with per-package rate limiting.
+func NewRateLimitWriter(inner OutputWriter, opts RateLimitOptions) *RateLimitWriter {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+
+	if limit, ok := opts.Packages["*"]; ok {
+		opts.Limit = limit
+	}
+
+	return &RateLimitWriter{
+		inner:  inner,
+		opts:   opts,
+		counts: map[string]int{},
+	}
+}
+
+func (rateLimitWriter *RateLimitWriter) Init() {
+	rateLimitWriter.inner.Init()
+}
+
+func (rateLimitWriter *RateLimitWriter) Write(log *Log) {
+	if rateLimitWriter.Allow(log.Package) {
+		rateLimitWriter.inner.Write(log)
+	}
+}
+
+// Allow counts a log for the package and reports whether it's within its limit.
+func (rateLimitWriter *RateLimitWriter) Allow(pkg string) bool {
+	limit := rateLimitWriter.limitFor(pkg)
+	if limit <= 0 {
+		return true
+	}
+
+	rateLimitWriter.mutex.Lock()
+	defer rateLimitWriter.mutex.Unlock()
+
+	now := Now()
+	if now >= rateLimitWriter.resetAt {
+		rateLimitWriter.counts = map[string]int{}
+		rateLimitWriter.resetAt = now + int64(rateLimitWriter.opts.Interval)
+	}
+
+	rateLimitWriter.counts[pkg]++
+	return rateLimitWriter.counts[pkg] <= limit
+}
+
+func (rateLimitWriter *RateLimitWriter) limitFor(pkg string) int {
+	name, ok := inheritedName(pkg, func(name string) bool {
+		_, ok := rateLimitWriter.opts.Packages[name]
+		return ok
+	})
+	if ok {
+		return rateLimitWriter.opts.Packages[name]
+	}
+
+	return rateLimitWriter.opts.Limit
+}
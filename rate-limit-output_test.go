@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimitWriterPackages(t *testing.T) {
+	rateLimitWriter := NewRateLimitWriter(&recordWriter{}, RateLimitOptions{
+		Limit:    5,
+		Packages: map[string]int{"db": 2, "db.pool": 3, "http": 0},
+		Interval: time.Hour,
+	})
+
+	tests := []struct {
+		pkg     string
+		allowed int
+	}{
+		{"db", 2},
+		{"db.query", 2},
+		{"db.query.slow", 2},
+		{"db.pool", 3},
+		{"db.pool.conn", 3},
+		{"dbx", 5},
+		{"http", 10},
+		{"http.client", 10},
+		{"cache", 5},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pkg, func(t *testing.T) {
+			allowed := 0
+			for i := 0; i < 10; i++ {
+				if rateLimitWriter.Allow(test.pkg) {
+					allowed++
+				}
+			}
+
+			if allowed != test.allowed {
+				t.Errorf("allowed %d of 10 logs, want %d", allowed, test.allowed)
+			}
+		})
+	}
+}
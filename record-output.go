@@ -0,0 +1,48 @@
+package logger
+
+import "sync"
+
+// recordWriter keeps a clone of every log it receives.
+type recordWriter struct {
+	mutex sync.Mutex
+	logs  []*Log
+	done  bool
+}
+
+func (recordWriter *recordWriter) Init() {}
+
+func (recordWriter *recordWriter) Write(log *Log) {
+	recordWriter.mutex.Lock()
+	defer recordWriter.mutex.Unlock()
+
+	if !recordWriter.done {
+		recordWriter.logs = append(recordWriter.logs, log.Clone())
+	}
+}
+
+// RecordAll captures the logs of every logger writing to the runtime, e.g. in an
+// integration test spanning several packages, next to the current writers. The
+// logs should be read once stop has returned, which removes the recorder:
+//
+//	stop, logs := logger.RecordAll()
+//	run()
+//	stop()
+//	for _, log := range *logs { ... }
+//
+// Loggers with their own Writer, and routed packages, aren't recorded.
+func RecordAll() (stop func(), logs *[]*Log) {
+	recorder := &recordWriter{}
+	Hook(recorder)
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			Unhook(recorder)
+
+			recorder.mutex.Lock()
+			recorder.done = true
+			recorder.mutex.Unlock()
+		})
+	}, &recorder.logs
+}
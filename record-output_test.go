@@ -0,0 +1,47 @@
+package logger
+
+import "testing"
+
+func TestRecordAll(t *testing.T) {
+	keepWriters(t)
+
+	current := &recordWriter{}
+	runtime.setWriters(func([]OutputWriter) []OutputWriter {
+		return []OutputWriter{current}
+	})
+
+	own, _ := recordLogger("own")
+
+	stop, logs := RecordAll()
+	New("db").Info("connected")
+	New("http").Error("failed")
+	own.Info("not recorded")
+	stop()
+	stop()
+
+	New("db").Info("after stop")
+
+	if len(*logs) != 2 {
+		t.Fatalf("recorded %d logs, want 2", len(*logs))
+	}
+
+	tests := []struct {
+		pkg     string
+		level   string
+		message string
+	}{
+		{"db", "INFO", "connected"},
+		{"http", "ERROR", "failed"},
+	}
+
+	for i, test := range tests {
+		log := (*logs)[i]
+		if log.Package != test.pkg || log.Level != test.level || log.Message != test.message {
+			t.Errorf("log %d is %s %s %q, want %s %s %q", i, log.Package, log.Level, log.Message, test.pkg, test.level, test.message)
+		}
+	}
+
+	if len(current.logs) != 3 {
+		t.Errorf("the current writer got %d logs, want all 3 logs of the runtime", len(current.logs))
+	}
+}
@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"sort"
+	"sync"
+)
+
+// registeredNames holds the names of the loggers created by New. Only names are
+// kept, so that loggers can still be garbage collected.
+var (
+	registeredNames = map[string]struct{}{}
+	registryMutex   sync.Mutex
+)
+
+func register(name string) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	registeredNames[name] = struct{}{}
+}
+
+// RegisteredLoggers returns the sorted names of the loggers created by New so
+// far, e.g. for a UI listing the packages whose levels can be changed.
+func RegisteredLoggers() []string {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	names := make([]string, 0, len(registeredNames))
+	for name := range registeredNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// Replay reads NDJSON logs as written by the JSON format, e.g. from an archived
+// file, and writes them to w, a pretty standard output for viewing them colorized
+// or filtering them with a LOG spec. The level is read from the LevelKey of w,
+// which should be the one the logs were written with. Lines that aren't JSON logs
+// are skipped and counted. The error is the one of reading r, if any.
+func Replay(r io.Reader, w OutputWriter) (skipped int, err error) {
+	reader := bufio.NewReader(r)
+
+	levelKey := "level"
+	if standardWriter := asStandardWriter(w); standardWriter != nil {
+		levelKey = standardWriter.levelKey()
+	}
+
+	for {
+		line, err := reader.ReadBytes('\n')
+
+		if len(bytes.TrimSpace(line)) > 0 {
+			if log, parseErr := parseJSONLog(line, levelKey); parseErr == nil {
+				w.Write(log)
+			} else {
+				skipped++
+			}
+		}
+
+		if err == io.EOF {
+			return skipped, nil
+		}
+
+		if err != nil {
+			return skipped, err
+		}
+	}
+}
+
+// parseJSONLog reads a log written by the JSON format, whose level is the field
+// named levelKey.
+func parseJSONLog(line []byte, levelKey string) (*Log, error) {
+	log, err := parseJSONFields(line)
+	if err != nil || levelKey == "level" {
+		return log, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return nil, err
+	}
+
+	log.Level = ""
+	if level, ok := fields[levelKey]; ok {
+		if err := json.Unmarshal(level, &log.Level); err != nil {
+			return nil, err
+		}
+	}
+
+	return log, nil
+}
+
+// parseJSONFields reads the fields of a log written by the JSON format. The time
+// can be in Unix nanoseconds, the default, or an RFC 3339 string, as written with
+// JSONTimeFormat set to time.RFC3339Nano.
+func parseJSONFields(line []byte) (*Log, error) {
+	log := &Log{}
+	err := json.Unmarshal(line, log)
+	if err == nil {
+		return log, nil
+	}
+
+	withTime := struct {
+		*Log
+		Time string `json:"time"`
+	}{Log: &Log{}}
+
+	if json.Unmarshal(line, &withTime) != nil {
+		return nil, err
+	}
+
+	t, timeErr := time.Parse(time.RFC3339Nano, withTime.Time)
+	if timeErr != nil {
+		return nil, err
+	}
+
+	withTime.Log.Time = t.UnixNano()
+	return withTime.Log, nil
+}
@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReplayLevelKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		levelKey string
+	}{
+		{"Default", ""},
+		{"Severity", "severity"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var archived bytes.Buffer
+			writer := Config{Format: FormatJSON, LevelKey: test.levelKey}.standardWriter(&archived)
+			writer.Write(&Log{Package: "db", Level: "WARN", Message: "slow query", Time: 1})
+			writer.Write(&Log{Package: "db", Level: "DEBUG", Message: "connected", Time: 2})
+
+			var replayed bytes.Buffer
+			viewer := Config{Format: FormatJSON, LevelKey: test.levelKey, Levels: "*@warn"}.standardWriter(&replayed)
+
+			skipped, err := Replay(strings.NewReader(archived.String()), viewer)
+			if err != nil || skipped != 0 {
+				t.Fatalf("Replay() = %d, %v, want 0, nil", skipped, err)
+			}
+
+			want := strings.SplitAfter(archived.String(), "\n")[0]
+			if replayed.String() != want {
+				t.Errorf("replayed %q, want the WARN log %q", replayed.String(), want)
+			}
+		})
+	}
+}
@@ -2,64 +2,236 @@ package logger
 
 import (
 	"os"
+	"sync"
+	"sync/atomic"
 )
 
 var (
-	runtime *Runtime
-	muted   = &OutputSettings{}
-	verbose = &OutputSettings{
+	runtime  *Runtime
+	sequence uint64
+	muted    = &OutputSettings{}
+	verbose  = &OutputSettings{
 		Info:  true,
 		Timer: true,
+		Warn:  true,
 		Error: true,
 	}
 )
 
 func init() {
+	writer := NewStandardOutput(os.Stderr)
+	writer.Init()
+
 	runtime = &Runtime{
-		Writers: []OutputWriter{
-			NewStandardOutput(os.Stderr),
-		},
+		Writers: []OutputWriter{writer},
 	}
 }
 
+// OutputWriter receives every log emitted by the runtime. The *Log passed to Write
+// is only valid for the duration of the call; writers that retain it must Clone it.
 type OutputWriter interface {
 	Init()
 	Write(log *Log)
 }
 
+// Flusher is implemented by writers that buffer output and can flush it on demand.
+type Flusher interface {
+	Flush() error
+}
+
 type OutputSettings struct {
+	Debug bool
 	Info  bool
 	Timer bool
+	Warn  bool
 	Error bool
 }
 
+// String returns the LOG spec level producing these settings, e.g. "timer" or
+// "mute", the inverse of parseVerbosityLevel. Settings that no single level
+// produces, e.g. debug and error only, are named after their lowest enabled
+// level, so that the result always parses.
+func (settings OutputSettings) String() string {
+	switch {
+	case settings.Debug:
+		return "debug"
+	case settings.Info:
+		return "info"
+	case settings.Timer:
+		return "timer"
+	case settings.Warn:
+		return "warn"
+	case settings.Error:
+		return "error"
+	}
+
+	return "mute"
+}
+
 type Runtime struct {
 	Writers []OutputWriter
+
+	// routes maps package names to the writer their logs are sent to instead of
+	// Writers, held as single-element slices.
+	routes map[string][]OutputWriter
+
+	// mutex guards Writers and routes, which are replaced rather than modified in
+	// place so that logs can be written outside of the lock.
+	mutex sync.RWMutex
 }
 
 func (runtime *Runtime) Log(log *Log) {
-	if len(runtime.Writers) == 0 {
+	// Assigned before any writer filters the log, so that gaps in the sequence
+	// only come from logs lost in transport.
+	log.Seq = atomic.AddUint64(&sequence, 1)
+	trackErrors(log)
+
+	writers := runtime.writersFor(log.Package)
+	if len(writers) == 0 {
 		return
 	}
 
+	if breaker.hold(log) {
+		return
+	}
+
+	pending, dropped := breaker.record(!writeAll(writers, log))
+	if pending == nil && dropped == 0 {
+		return
+	}
+
+	// The writers recovered: write the logs held meanwhile, after this one.
+	for _, held := range pending {
+		writeAll(runtime.writersFor(held.Package), held)
+	}
+
+	if dropped > 0 {
+		runtime.Log(&Log{
+			Package: "logger",
+			Level:   "WARN",
+			Message: "Logs dropped while the writers were failing",
+			Time:    Now(),
+			Attrs:   &Attrs{"dropped": dropped},
+			notice:  true,
+		})
+	}
+}
+
+// writeAll writes the log to each of writers, returning false if all of them failed.
+func writeAll(writers []OutputWriter, log *Log) bool {
 	// Avoid getting into a loop if there is just one writer
-	if len(runtime.Writers) == 1 {
-		runtime.Writers[0].Write(log)
-	} else {
-		for _, w := range runtime.Writers {
-			w.Write(log)
+	if len(writers) == 1 {
+		return writeChild(writers[0], log) == nil
+	}
+
+	written := false
+	for _, w := range writers {
+		if writeChild(w, log) == nil {
+			written = true
 		}
 	}
+
+	return written
+}
+
+// emit sends the log to writer, or to the runtime when writer is nil.
+func emit(writer OutputWriter, log *Log) {
+	if writer == nil {
+		runtime.Log(log)
+		return
+	}
+
+	log.Seq = atomic.AddUint64(&sequence, 1)
+	trackErrors(log)
+	writer.Write(log)
+}
+
+func (runtime *Runtime) writers() []OutputWriter {
+	runtime.mutex.RLock()
+	defer runtime.mutex.RUnlock()
+
+	return runtime.Writers
+}
+
+// writersFor returns the writers of logs from pkg: its route if there's one, the
+// default writers otherwise.
+func (runtime *Runtime) writersFor(pkg string) []OutputWriter {
+	runtime.mutex.RLock()
+	defer runtime.mutex.RUnlock()
+
+	if route, ok := runtime.routes[pkg]; ok {
+		return route
+	}
+
+	return runtime.Writers
+}
+
+// setWriters replaces the writers with the result of update, which must return a
+// new slice rather than modify the one it's given.
+func (runtime *Runtime) setWriters(update func([]OutputWriter) []OutputWriter) {
+	runtime.mutex.Lock()
+	defer runtime.mutex.Unlock()
+
+	runtime.Writers = update(runtime.Writers)
 }
 
 // Add a new writer
 func Hook(writer OutputWriter) {
 	writer.Init()
-	runtime.Writers = append(runtime.Writers, writer)
+	runtime.setWriters(func(writers []OutputWriter) []OutputWriter {
+		return append(writers[:len(writers):len(writers)], writer)
+	})
+}
+
+// Unhook removes a writer added by Hook.
+func Unhook(writer OutputWriter) {
+	runtime.setWriters(func(writers []OutputWriter) []OutputWriter {
+		result := make([]OutputWriter, 0, len(writers))
+		for _, w := range writers {
+			if w != writer {
+				result = append(result, w)
+			}
+		}
+		return result
+	})
 }
 
 // Legacy method
 func SetOutput(file *os.File) {
 	writer := NewStandardOutput(file)
-	runtime.Writers[0] = writer
+	writer.Init()
+	runtime.setWriters(func(writers []OutputWriter) []OutputWriter {
+		if len(writers) == 0 {
+			return []OutputWriter{writer}
+		}
+
+		result := append([]OutputWriter(nil), writers...)
+		result[0] = writer
+		return result
+	})
+}
+
+// Route sends the logs of every logger named pkg to writer instead of the default
+// writers, e.g. to keep audit logs in a separate sink. A nil writer removes the
+// route. Loggers with their own Writer aren't affected.
+func Route(pkg string, writer OutputWriter) {
+	if writer != nil {
+		writer.Init()
+	}
+
+	runtime.mutex.Lock()
+	defer runtime.mutex.Unlock()
+
+	routes := make(map[string][]OutputWriter, len(runtime.routes)+1)
+	for name, route := range runtime.routes {
+		routes[name] = route
+	}
+
+	if writer == nil {
+		delete(routes, pkg)
+	} else {
+		routes[pkg] = []OutputWriter{writer}
+	}
+
+	runtime.routes = routes
 }
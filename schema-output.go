@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SchemaOptions configures a SchemaWriter.
+type SchemaOptions struct {
+	// AllowedKeys lists the attribute keys that are kept. A key ending with "*"
+	// allows every key starting with what precedes it, e.g. "http.*".
+	AllowedKeys []string
+
+	// Warn prints a warning to stderr the first time a key is dropped, naming the
+	// dropped keys.
+	Warn bool
+}
+
+// SchemaWriter forwards logs to an inner writer with only the attributes whose
+// keys are allowed, so that an unexpected field (e.g. personal data) can't be
+// logged by accident.
+type SchemaWriter struct {
+	inner    OutputWriter
+	opts     SchemaOptions
+	exact    map[string]bool
+	prefixes []string
+
+	mutex  sync.Mutex
+	warned map[string]bool
+	output io.Writer
+}
+
+// NewSchemaWriter wraps inner with the allow-list of attribute keys.
+func NewSchemaWriter(inner OutputWriter, opts SchemaOptions) *SchemaWriter {
+	schemaWriter := &SchemaWriter{
+		inner:  inner,
+		opts:   opts,
+		exact:  map[string]bool{},
+		warned: map[string]bool{},
+		output: os.Stderr,
+	}
+
+	for _, key := range opts.AllowedKeys {
+		if strings.HasSuffix(key, "*") {
+			schemaWriter.prefixes = append(schemaWriter.prefixes, strings.TrimSuffix(key, "*"))
+		} else {
+			schemaWriter.exact[key] = true
+		}
+	}
+
+	return schemaWriter
+}
+
+func (schemaWriter *SchemaWriter) Init() {
+	schemaWriter.inner.Init()
+}
+
+func (schemaWriter *SchemaWriter) Write(log *Log) {
+	if log.Attrs == nil {
+		schemaWriter.inner.Write(log)
+		return
+	}
+
+	var dropped []string
+	for key := range *log.Attrs {
+		if !schemaWriter.Allowed(key) {
+			dropped = append(dropped, key)
+		}
+	}
+
+	if len(dropped) == 0 {
+		schemaWriter.inner.Write(log)
+		return
+	}
+
+	// Copy the log rather than modifying it, as other writers receive it too.
+	stripped := *log
+	attrs := make(Attrs, len(*log.Attrs)-len(dropped))
+	for key, val := range *log.Attrs {
+		if schemaWriter.Allowed(key) {
+			attrs[key] = val
+		}
+	}
+	stripped.Attrs = &attrs
+
+	if schemaWriter.opts.Warn {
+		schemaWriter.warn(log.Package, dropped)
+	}
+
+	schemaWriter.inner.Write(&stripped)
+}
+
+// Allowed reports whether the attribute key is kept.
+func (schemaWriter *SchemaWriter) Allowed(key string) bool {
+	if schemaWriter.exact[key] {
+		return true
+	}
+
+	for _, prefix := range schemaWriter.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// warn prints the keys that haven't been warned about yet.
+func (schemaWriter *SchemaWriter) warn(pkg string, dropped []string) {
+	schemaWriter.mutex.Lock()
+	defer schemaWriter.mutex.Unlock()
+
+	var fresh []string
+	for _, key := range dropped {
+		if !schemaWriter.warned[key] {
+			schemaWriter.warned[key] = true
+			fresh = append(fresh, key)
+		}
+	}
+
+	if len(fresh) == 0 {
+		return
+	}
+
+	sort.Strings(fresh)
+	fmt.Fprintf(schemaWriter.output, "logger: dropped attributes not in the schema from %q: %s\n", pkg, strings.Join(fresh, ", "))
+}
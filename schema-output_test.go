@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestSchemaWriterAllowed(t *testing.T) {
+	schemaWriter := NewSchemaWriter(&recordWriter{}, SchemaOptions{AllowedKeys: []string{"user_id", "http.*"}})
+
+	tests := []struct {
+		key     string
+		allowed bool
+	}{
+		{"user_id", true},
+		{"user_id2", false},
+		{"user", false},
+		{"http.status", true},
+		{"http.", true},
+		{"http", false},
+		{"email", false},
+	}
+
+	for _, test := range tests {
+		if got := schemaWriter.Allowed(test.key); got != test.allowed {
+			t.Errorf("Allowed(%q) = %v, want %v", test.key, got, test.allowed)
+		}
+	}
+}
+
+func TestSchemaWriterWrite(t *testing.T) {
+	var warnings bytes.Buffer
+	recorder := &recordWriter{}
+	schemaWriter := NewSchemaWriter(recorder, SchemaOptions{AllowedKeys: []string{"user_id", "http.*"}, Warn: true})
+	schemaWriter.output = &warnings
+
+	original := &Log{Package: "api", Message: "signup", Attrs: &Attrs{"user_id": 1, "http.status": 201, "email": "a@b.c", "password": "x"}}
+	schemaWriter.Write(original)
+	schemaWriter.Write(&Log{Package: "api", Message: "again", Attrs: &Attrs{"email": "a@b.c"}})
+	schemaWriter.Write(&Log{Package: "api", Message: "plain"})
+
+	if len(recorder.logs) != 3 {
+		t.Fatalf("wrote %d logs, want 3", len(recorder.logs))
+	}
+
+	if want := (Attrs{"user_id": 1, "http.status": 201}); !reflect.DeepEqual(*recorder.logs[0].Attrs, want) {
+		t.Errorf("kept %v, want %v", *recorder.logs[0].Attrs, want)
+	}
+
+	if len(*original.Attrs) != 4 {
+		t.Errorf("the original log was modified: %v", *original.Attrs)
+	}
+
+	want := "logger: dropped attributes not in the schema from \"api\": email, password\n"
+	if warnings.String() != want {
+		t.Errorf("warned %q, want %q once", warnings.String(), want)
+	}
+}
@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"os"
+	"sync"
+)
+
+// settingsMutex guards the Settings maps of the standard writers, which can be
+// changed at runtime.
+var settingsMutex sync.RWMutex
+
+// SetLevel sets the minimum level of every package, e.g. "warn". It replaces the
+// "*" settings of the standard writers, and acts as a floor for packages that
+// have their own settings: they can be quieter than the given level, but not
+// more verbose. Their own settings are kept, so that setting a lower level again
+// gives them back. Writers wrapped by other writers aren't affected.
+func SetLevel(level string) {
+	floor := parseVerbosityLevel(level)
+
+	for _, writer := range runtime.writers() {
+		if standardWriter := asStandardWriter(writer); standardWriter != nil {
+			standardWriter.SetLevel(floor)
+		}
+	}
+}
+
+// SetLevel replaces the "*" settings and the Floor of the writer with floor. For
+// copies of a writer to share the floor, the Floor must have been set before the
+// copies were made, as done by NewStandardOutput.
+func (standardWriter *StandardWriter) SetLevel(floor *OutputSettings) {
+	settingsMutex.Lock()
+	defer settingsMutex.Unlock()
+
+	if standardWriter.Settings == nil {
+		standardWriter.Settings = map[string]*OutputSettings{}
+	}
+
+	if standardWriter.Floor == nil {
+		standardWriter.Floor = &OutputSettings{}
+	}
+	*standardWriter.Floor = *floor
+
+	all := *floor
+	standardWriter.Settings["*"] = &all
+}
+
+// Configure replaces the writer's package settings with the given LOG spec.
+// Packages without a level get the LOG_LEVEL default.
+func (standardWriter *StandardWriter) Configure(spec string) {
+	settings := parsePackageSettings(spec, parseVerbosityLevel(os.Getenv("LOG_LEVEL")))
+
+	settingsMutex.Lock()
+	defer settingsMutex.Unlock()
+
+	if standardWriter.Settings == nil {
+		standardWriter.Settings = map[string]*OutputSettings{}
+	}
+
+	for name := range standardWriter.Settings {
+		delete(standardWriter.Settings, name)
+	}
+
+	for name, s := range settings {
+		standardWriter.Settings[name] = s
+	}
+}
+
+// asStandardWriter returns the standard writer behind writer, if any. Copies of a
+// StandardWriter value share its Settings map, so changes made to the settings
+// through the returned pointer are seen by the original.
+func asStandardWriter(writer OutputWriter) *StandardWriter {
+	switch w := writer.(type) {
+	case *StandardWriter:
+		return w
+	case StandardWriter:
+		return &w
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSetLevelFloor(t *testing.T) {
+	var buf bytes.Buffer
+	writer := Config{Levels: "db@debug,*@info"}.standardWriter(&buf)
+
+	// A copy, like the one held by the runtime, sees the floor too.
+	copied := writer
+
+	steps := []struct {
+		level     string
+		dbDebug   bool
+		dbWarn    bool
+		otherInfo bool
+	}{
+		{"warn", false, true, false},
+		{"debug", true, true, true},
+		{"error", false, false, false},
+		{"debug", true, true, true},
+	}
+
+	for _, step := range steps {
+		writer.SetLevel(parseVerbosityLevel(step.level))
+
+		if got := copied.IsEnabled("db", "DEBUG"); got != step.dbDebug {
+			t.Errorf("after SetLevel(%q): db DEBUG enabled = %v, want %v", step.level, got, step.dbDebug)
+		}
+
+		if got := copied.IsEnabled("db.pool", "WARN"); got != step.dbWarn {
+			t.Errorf("after SetLevel(%q): db.pool WARN enabled = %v, want %v", step.level, got, step.dbWarn)
+		}
+
+		if got := copied.IsEnabled("http", "INFO"); got != step.otherInfo {
+			t.Errorf("after SetLevel(%q): http INFO enabled = %v, want %v", step.level, got, step.otherInfo)
+		}
+	}
+
+	if settings := writer.Settings["db"]; !settings.Debug {
+		t.Errorf("the db settings were changed to %v", settings)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !go1.21
+// +build !go1.21
+
+package logger
+
+// slogAttrs reports that arg isn't a slog.Attr, as log/slog requires Go 1.21.
+func slogAttrs(arg interface{}) (Attrs, bool) {
+	return nil, false
+}
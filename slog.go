@@ -0,0 +1,43 @@
+//go:build go1.21
+// +build go1.21
+
+package logger
+
+import "log/slog"
+
+// slogAttrs converts a slog.Attr arg into attributes, so that slog.String("k", v)
+// and the like can be passed to the logging methods. Groups are flattened into
+// dotted keys, e.g. slog.Group("req", "id", 1) becomes "req.id".
+func slogAttrs(arg interface{}) (Attrs, bool) {
+	attr, ok := arg.(slog.Attr)
+	if !ok {
+		return nil, false
+	}
+
+	attrs := Attrs{}
+	addSlogAttr(attrs, "", attr)
+	return attrs, true
+}
+
+func addSlogAttr(attrs Attrs, prefix string, attr slog.Attr) {
+	value := attr.Value.Resolve()
+
+	if value.Kind() == slog.KindGroup {
+		// As with slog, a group without a key is inlined into its parent.
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+
+		for _, child := range value.Group() {
+			addSlogAttr(attrs, prefix, child)
+		}
+		return
+	}
+
+	// Attrs without a key are ignored, as slog does.
+	if attr.Key == "" {
+		return
+	}
+
+	attrs[prefix+attr.Key] = value.Any()
+}
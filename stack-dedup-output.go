@@ -0,0 +1,181 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// StackKey is the attribute holding the stack trace of a log, as set by Go.
+const StackKey = "stack"
+
+// StackRefKey is the attribute referencing a stack trace written earlier. See
+// StackDedupWriter.
+const StackRefKey = "stack_ref"
+
+// StackDedupOptions configures a StackDedupWriter.
+type StackDedupOptions struct {
+	// Window is how long a stack trace is referenced rather than repeated once
+	// written in full. Defaults to a minute.
+	Window time.Duration
+
+	// CacheSize bounds the number of stack traces remembered, forgetting the
+	// oldest ones first. Defaults to 256.
+	CacheSize int
+}
+
+// StackDedupWriter forwards logs to an inner writer, writing each distinct stack
+// trace in full once per window. Both the full trace and later occurrences carry
+// a short "stack_ref" ID, so that a log whose trace was left out can be matched
+// with the one that has it, e.g. when the same error recurs in a loop.
+//
+// Stack traces are taken from the "stack" attribute set by Go, or else from the
+// error attributes whose "%+v" form, as rendered with VerboseErrors, has more to
+// it than their message. Left out, such an error is rendered by its message.
+type StackDedupWriter struct {
+	inner  OutputWriter
+	opts   StackDedupOptions
+	mutex  sync.Mutex
+	stacks map[uint64]int64
+}
+
+// NewStackDedupWriter wraps inner with deduplication of stack traces.
+func NewStackDedupWriter(inner OutputWriter, opts StackDedupOptions) *StackDedupWriter {
+	if opts.Window <= 0 {
+		opts.Window = time.Minute
+	}
+
+	if opts.CacheSize <= 0 {
+		opts.CacheSize = 256
+	}
+
+	return &StackDedupWriter{
+		inner:  inner,
+		opts:   opts,
+		stacks: map[uint64]int64{},
+	}
+}
+
+func (stackDedupWriter *StackDedupWriter) Init() {
+	stackDedupWriter.inner.Init()
+}
+
+func (stackDedupWriter *StackDedupWriter) Write(log *Log) {
+	if log.Attrs == nil {
+		stackDedupWriter.inner.Write(log)
+		return
+	}
+
+	key, stack := findStack(*log.Attrs)
+	if stack == "" {
+		stackDedupWriter.inner.Write(log)
+		return
+	}
+
+	sum := fnv64a(stack)
+
+	// Copy the log rather than modify it, as other writers receive it too.
+	deduped := *log
+	attrs := mergeAttrs(nil, *log.Attrs)
+	attrs[StackRefKey] = fmt.Sprintf("%08x", uint32(sum))
+	if !stackDedupWriter.full(sum) {
+		if key == StackKey {
+			delete(attrs, StackKey)
+		} else {
+			attrs[key] = messageError{attrs[key].(error)}
+		}
+	}
+	deduped.Attrs = &attrs
+
+	stackDedupWriter.inner.Write(&deduped)
+}
+
+// findStack returns the attribute holding the stack trace of the log and the
+// trace, preferring the "stack" attribute to error attributes, which are taken in
+// key order.
+func findStack(attrs Attrs) (string, string) {
+	if stack, ok := attrs[StackKey].(string); ok && stack != "" {
+		return StackKey, stack
+	}
+
+	var keys []string
+	for key, val := range attrs {
+		if _, ok := val.(error); ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if stack := verboseError(attrs[key].(error)); stack != "" {
+			return key, stack
+		}
+	}
+
+	return "", ""
+}
+
+// verboseError returns the "%+v" form of err if it has more to it than the
+// message, and "" otherwise or if formatting err panics.
+func verboseError(err error) (verbose string) {
+	defer func() {
+		if recover() != nil {
+			verbose = ""
+		}
+	}()
+
+	if verbose = fmt.Sprintf("%+v", err); verbose == err.Error() {
+		return ""
+	}
+
+	return verbose
+}
+
+// messageError is an error whose stack trace was left out: "%+v" only renders
+// its message. Its causes are kept.
+type messageError struct {
+	err error
+}
+
+func (err messageError) Error() string {
+	return err.err.Error()
+}
+
+func (err messageError) Unwrap() error {
+	return errors.Unwrap(err.err)
+}
+
+// full reports whether the stack trace with the given hash is to be written in
+// full, i.e. it wasn't within the window.
+func (stackDedupWriter *StackDedupWriter) full(sum uint64) bool {
+	stackDedupWriter.mutex.Lock()
+	defer stackDedupWriter.mutex.Unlock()
+
+	now := time.Now().UnixNano()
+
+	if writtenAt, ok := stackDedupWriter.stacks[sum]; ok && now-writtenAt < int64(stackDedupWriter.opts.Window) {
+		return false
+	}
+
+	if _, ok := stackDedupWriter.stacks[sum]; !ok && len(stackDedupWriter.stacks) >= stackDedupWriter.opts.CacheSize {
+		stackDedupWriter.evictOldest()
+	}
+
+	stackDedupWriter.stacks[sum] = now
+	return true
+}
+
+func (stackDedupWriter *StackDedupWriter) evictOldest() {
+	var oldest uint64
+	var oldestAt int64
+
+	for sum, writtenAt := range stackDedupWriter.stacks {
+		if oldestAt == 0 || writtenAt < oldestAt {
+			oldest, oldestAt = sum, writtenAt
+		}
+	}
+
+	delete(stackDedupWriter.stacks, oldest)
+}
@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// stackError renders a fake stack trace with "%+v", like github.com/pkg/errors.
+type stackError struct {
+	cause error
+}
+
+func (err stackError) Error() string {
+	return "query failed"
+}
+
+func (err stackError) Unwrap() error {
+	return err.cause
+}
+
+func (err stackError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		fmt.Fprint(s, "query failed\nmain.query\n\tmain.go:42")
+		return
+	}
+
+	fmt.Fprint(s, err.Error())
+}
+
+func TestStackDedupVerboseErrors(t *testing.T) {
+	recorder := &recordWriter{}
+	stackDedupWriter := NewStackDedupWriter(recorder, StackDedupOptions{})
+	formatter := &StandardWriter{VerboseErrors: true}
+
+	err := stackError{cause: errors.New("connection reset")}
+	for i := 0; i < 2; i++ {
+		stackDedupWriter.Write(&Log{Level: "ERROR", Message: "failed", Attrs: &Attrs{"err": err}})
+	}
+
+	if len(recorder.logs) != 2 {
+		t.Fatalf("got %d logs, want 2", len(recorder.logs))
+	}
+
+	first, second := formatter.JSONFormat(recorder.logs[0]), formatter.JSONFormat(recorder.logs[1])
+
+	if !strings.Contains(first, "main.go:42") {
+		t.Errorf("first log %s doesn't have the stack trace", first)
+	}
+
+	if strings.Contains(second, "main.go:42") || !strings.Contains(second, `"err":"query failed"`) {
+		t.Errorf("second log %s repeats the stack trace", second)
+	}
+
+	ref := (*recorder.logs[0].Attrs)[StackRefKey]
+	if ref == nil || ref != (*recorder.logs[1].Attrs)[StackRefKey] {
+		t.Errorf("stack refs %v and %v don't match", ref, (*recorder.logs[1].Attrs)[StackRefKey])
+	}
+
+	if !strings.Contains(second, `"err_cause":["connection reset"]`) {
+		t.Errorf("second log %s lost the cause of the error", second)
+	}
+}
+
+func TestStackDedupStackKey(t *testing.T) {
+	recorder := &recordWriter{}
+	stackDedupWriter := NewStackDedupWriter(recorder, StackDedupOptions{})
+
+	for i := 0; i < 2; i++ {
+		stackDedupWriter.Write(&Log{Level: "ERROR", Attrs: &Attrs{StackKey: "goroutine 1 [running]:"}})
+	}
+
+	if _, ok := (*recorder.logs[0].Attrs)[StackKey]; !ok {
+		t.Error("first log doesn't have the stack trace")
+	}
+
+	if _, ok := (*recorder.logs[1].Attrs)[StackKey]; ok {
+		t.Error("second log repeats the stack trace")
+	}
+}
+
+func TestStackDedupPlainErrors(t *testing.T) {
+	recorder := &recordWriter{}
+	stackDedupWriter := NewStackDedupWriter(recorder, StackDedupOptions{})
+
+	stackDedupWriter.Write(&Log{Level: "ERROR", Attrs: &Attrs{"err": errors.New("plain")}})
+
+	if _, ok := (*recorder.logs[0].Attrs)[StackRefKey]; ok {
+		t.Error("an error without a stack trace got a stack ref")
+	}
+}
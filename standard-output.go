@@ -1,66 +1,407 @@
 package logger
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 )
 
+// Layouts for StandardWriter.TimeFormat with the given sub-second precision.
+const (
+	TimePrecisionSeconds = "15:04:05"
+	TimePrecisionMillis  = "15:04:05.000"
+	TimePrecisionMicros  = "15:04:05.000000"
+	TimePrecisionNanos   = "15:04:05.000000000"
+)
+
+// DefaultTimeFormat is the layout used for timestamps in pretty output.
+const DefaultTimeFormat = TimePrecisionMillis
+
+// Output formats accepted by StandardWriter.OutputFormat and the LOG_FORMAT envvar.
+const (
+	FormatPretty = "pretty"
+	FormatJSON   = "json"
+	FormatLogfmt = "logfmt"
+	FormatECS    = "ecs"
+)
+
+// NewStandardOutput returns a standard output writing to file, configured by the
+// LOG, LOG_JSON, LOG_LEVEL and LOG_FORMAT envvars (see ConfigFromEnv).
 func NewStandardOutput(file *os.File) OutputWriter {
-	var writer = StandardWriter{
-		ColorsEnabled: true,
-		Target:        file,
-	}
+	return NewStandardOutputWriter(file)
+}
 
-	defaultOutputSettings := parseVerbosityLevel(os.Getenv("LOG_LEVEL"))
-	logEnv := "*"
-	if os.Getenv("LOG") != "" {
-		logEnv = os.Getenv("LOG")
+// NewStandardOutputWriter returns a standard output writing to w, configured by
+// the envvars like NewStandardOutput, e.g. for the captured output of a test
+// framework. Colors and pretty output are only enabled by default when w is a
+// terminal.
+func NewStandardOutputWriter(w io.Writer) OutputWriter {
+	return ConfigFromEnv().standardWriter(w)
+}
+
+// NewStandardOutputWithSpec returns a standard output writing to w, showing the
+// packages and levels selected by logSpec and defaultLevel instead of the LOG and
+// LOG_LEVEL envvars. This lets each writer of a fan-out have its own levels, e.g.
+// debug logs on the console but only info and up in a file.
+func NewStandardOutputWithSpec(w io.Writer, logSpec, defaultLevel string) OutputWriter {
+	config := Config{
+		Format:       strings.ToLower(strings.TrimSpace(os.Getenv("LOG_FORMAT"))),
+		Levels:       logSpec,
+		DefaultLevel: defaultLevel,
 	}
-	writer.Settings = parsePackageSettings(logEnv, defaultOutputSettings)
 
-	return writer
+	return config.standardWriter(w)
+}
+
+// NewFDOutput returns a standard output writing to the given file descriptor, e.g.
+// fd 3 for sending logs to a sidecar while stdout carries the program's output.
+// The descriptor must be inherited by the process and open for writing.
+func NewFDOutput(fd uintptr) OutputWriter {
+	return NewStandardOutput(os.NewFile(fd, fmt.Sprintf("/dev/fd/%d", fd)))
 }
 
 type StandardWriter struct {
 	ColorsEnabled bool
-	Target        *os.File
+	Target        io.Writer
 	Settings      map[string]*OutputSettings
+
+	// Floor, when set, caps the levels of every package, as set by SetLevel:
+	// packages can be quieter than it, but not more verbose. Their Settings are
+	// left as they are. It's a pointer so that copies of the writer share it.
+	Floor *OutputSettings
+
+	// OutputFormat is one of FormatPretty, FormatJSON, FormatLogfmt or FormatECS. When
+	// empty, pretty output is used if colors are enabled and JSON otherwise.
+	OutputFormat string
+
+	// HideAttrs leaves the attributes out of pretty output. Other formats always
+	// include them.
+	HideAttrs bool
+
+	// Separator is placed between the time, label and message of pretty
+	// output. Defaults to a single space when empty.
+	Separator string
+
+	// PadPackage right-pads the pretty label to the given visible width so that
+	// messages from differently named packages line up. Zero disables padding.
+	PadPackage int
+
+	// TimeFormat is the layout used for the pretty timestamp and time.Time
+	// attributes. Defaults to DefaultTimeFormat when empty.
+	TimeFormat string
+
+	// DecimalBytes renders Bytes attributes with decimal (kB, MB) rather than
+	// binary (KiB, MiB) units.
+	DecimalBytes bool
+
+	// JSONTimeFormat, when set, makes JSON output carry the time as a string in
+	// this layout (e.g. time.RFC3339Nano) instead of Unix nanoseconds. It's also
+	// used for time.Time attributes, which otherwise use RFC 3339.
+	JSONTimeFormat string
+
+	// ColorFunc, when set, picks the ANSI color of each package's label instead of
+	// the default palette rotation.
+	ColorFunc func(pkg string) string
+
+	// VerboseErrors renders error attributes with "%+v", which includes stack
+	// traces for errors that support it, and adds the messages of the errors they
+	// wrap as a sibling "<key>_cause" attribute. See StackDedupWriter to write
+	// repeated stack traces once.
+	VerboseErrors bool
+
+	// MaxAttrs bounds the number of attributes of a log that are rendered, keeping
+	// the first ones in key order. Pretty output notes how many were left out, and
+	// the other formats add them up in an "_attrs_truncated" attribute. Zero means
+	// no limit.
+	MaxAttrs int
+
+	// StringerAttrs renders attribute values implementing fmt.Stringer with their
+	// String method in JSON output too, so that it agrees with pretty output. By
+	// default JSON uses their native marshaling, which keeps structured fields
+	// but may differ from what the terminal shows.
+	StringerAttrs bool
+
+	// NoNewline leaves out the newline ending every log, for transports framing
+	// messages themselves, e.g. with a length prefix.
+	NoNewline bool
+
+	// FloatPrecision renders float attributes with this many digits after the
+	// decimal point and never in exponent form, e.g. for amounts of money. Zero
+	// keeps the shortest representation. Integers are always rendered exactly,
+	// including int64 and uint64 values beyond 2^53, though JSON consumers that
+	// parse numbers as doubles (such as JavaScript) will round those.
+	FloatPrecision int
+
+	// SlowThreshold highlights the duration of pretty TIMER logs taking longer
+	// than this, so that latency outliers stand out. Zero disables it.
+	SlowThreshold time.Duration
+
+	// ExpandDots nests attributes with dotted keys in JSON output, e.g.
+	// "http.status" is written as {"http":{"status":200}}. Other formats keep
+	// the flat keys.
+	ExpandDots bool
+
+	// LevelKey names the level field of JSON and logfmt output, e.g. "severity"
+	// for backends expecting it. Defaults to "level". ECS output always uses
+	// "log.level", as the schema requires.
+	LevelKey string
+
+	// BinaryEncoding renders []byte attributes as BinaryHex, the default, or
+	// BinaryBase64 in pretty and logfmt output. JSON output uses base64.
+	BinaryEncoding string
+
+	// MaxBinaryAttr truncates []byte attributes to this many bytes. Pretty and
+	// logfmt output note how many were left out, and JSON output adds them up in
+	// a sibling "<key>_truncated" attribute. Zero means no limit; NewStandardOutput
+	// sets it to DefaultMaxBinaryAttr.
+	MaxBinaryAttr int
+
+	// MultilineAttrs renders pretty attribute values spanning several lines, like
+	// a YAML snippet, below the log line, indented under their key, instead of
+	// inline. Single-line values stay inline.
+	MultilineAttrs bool
+
+	// buffer holds the output on its way to Target, when set. See SetBuffering.
+	buffer *bufferedTarget
+}
+
+// BufferMode selects how a standard output buffers its writes. See SetBuffering.
+type BufferMode int
+
+const (
+	// BufferNone writes every log to the target right away, in a single write.
+	BufferNone BufferMode = iota
+
+	// BufferLine writes the output to the target at the end of every line. Each
+	// log being a single write, it only differs from BufferNone for logs without
+	// a trailing newline (see NoNewline), which wait for the next one.
+	BufferLine
+
+	// BufferBlock writes the output to the target once the buffer is full, or on
+	// Flush.
+	BufferBlock
+)
+
+// SetBuffering changes how the output buffers its writes to Target, flushing
+// what it had buffered. The default, BufferNone, keeps logs in order with what
+// the program writes to the same file itself, which matters on a terminal.
+// BufferBlock, with a buffer of size bytes (4KiB when zero), saves system calls
+// when logging heavily to a pipe or file, at the cost of logs showing up late and
+// out of order with the program's own prints, and of losing the buffered ones
+// if the program crashes. Call Flush before exiting.
+func (standardWriter *StandardWriter) SetBuffering(mode BufferMode, size int) {
+	if standardWriter.buffer != nil {
+		standardWriter.buffer.Flush()
+		standardWriter.buffer = nil
+	}
+
+	if mode == BufferNone {
+		return
+	}
+
+	if size <= 0 {
+		size = 4096
+	}
+
+	standardWriter.buffer = &bufferedTarget{
+		mode:   mode,
+		writer: bufio.NewWriterSize(standardWriter.Target, size),
+	}
+}
+
+// Flush writes the buffered output to the target, if buffering is enabled.
+func (standardWriter StandardWriter) Flush() error {
+	if standardWriter.buffer == nil {
+		return nil
+	}
+
+	return standardWriter.buffer.Flush()
+}
+
+// bufferedTarget buffers writes to the target of a standard output. It's shared
+// by the copies of the StandardWriter.
+type bufferedTarget struct {
+	mode   BufferMode
+	mutex  sync.Mutex
+	writer *bufio.Writer
 }
 
-func (standardWriter StandardWriter) Init() {}
+func (bufferedTarget *bufferedTarget) Write(p []byte) (int, error) {
+	bufferedTarget.mutex.Lock()
+	defer bufferedTarget.mutex.Unlock()
+
+	n, err := bufferedTarget.writer.Write(p)
+	if err == nil && bufferedTarget.mode == BufferLine && bytes.HasSuffix(p, []byte("\n")) {
+		err = bufferedTarget.writer.Flush()
+	}
+
+	return n, err
+}
+
+func (bufferedTarget *bufferedTarget) Flush() error {
+	bufferedTarget.mutex.Lock()
+	defer bufferedTarget.mutex.Unlock()
+
+	return bufferedTarget.writer.Flush()
+}
+
+// Init prints the effective configuration of the writer when the LOG_DEBUG_CONFIG
+// envvar is set, bypassing the package filters but respecting the output format.
+func (standardWriter StandardWriter) Init() {
+	if enabled, _ := strconv.ParseBool(os.Getenv("LOG_DEBUG_CONFIG")); !enabled {
+		return
+	}
+
+	standardWriter.print(standardWriter.Format(&Log{
+		Package: "logger",
+		Level:   "INFO",
+		Message: "Effective configuration",
+		Time:    Now(),
+		Attrs: &Attrs{
+			"log":    standardWriter.SettingsSpec(),
+			"format": standardWriter.OutputFormat,
+			"colors": standardWriter.ColorsEnabled,
+		},
+	}))
+}
 
 func (standardWriter StandardWriter) Write(log *Log) {
-	if standardWriter.IsEnabled(log.Package, log.Level) {
-		fmt.Fprintln(standardWriter.Target, standardWriter.Format(log))
+	if log.notice || standardWriter.IsEnabled(log.Package, log.Level) {
+		standardWriter.print(standardWriter.Format(log))
+	}
+}
+
+// TryWrite is like Write, returning the error of writing to the target.
+func (standardWriter StandardWriter) TryWrite(log *Log) error {
+	if !log.notice && !standardWriter.IsEnabled(log.Package, log.Level) {
+		return nil
+	}
+
+	return standardWriter.tryPrint(standardWriter.Format(log))
+}
+
+// print writes a formatted log to the target, followed by a newline unless
+// NoNewline is set.
+func (standardWriter StandardWriter) print(line string) {
+	standardWriter.tryPrint(line)
+}
+
+func (standardWriter StandardWriter) tryPrint(line string) error {
+	var target io.Writer = standardWriter.Target
+	if standardWriter.buffer != nil {
+		target = standardWriter.buffer
 	}
+
+	var err error
+	if standardWriter.NoNewline {
+		_, err = fmt.Fprint(target, line)
+	} else {
+		_, err = fmt.Fprintln(target, line)
+	}
+
+	return err
 }
 
+// IsEnabled reports whether logs of the given level from the logger are shown.
+// Levels are matched regardless of case, and "WARNING" is read as "WARN".
+// Unregistered custom levels are always shown, with a warning the first time.
 func (standardWriter *StandardWriter) IsEnabled(logger, level string) bool {
 	settings := standardWriter.LoggerSettings(logger)
 
-	if level == "INFO" {
+	switch name := strings.ToUpper(level); name {
+	case "DEBUG":
+		return settings.Debug
+	case "INFO", "":
 		return settings.Info
+	case "WARN", "WARNING":
+		return settings.Warn
+	case "ERROR":
+		return settings.Error
+	case "TIMER":
+		return settings.Timer
+	default:
+		level = name
 	}
 
-	if level == "ERROR" {
-		return settings.Error
+	custom, ok := levels.Get(level)
+	if !ok {
+		levels.WarnUnknown(level)
+		return true
 	}
 
-	if level == "TIMER" {
-		return settings.Timer
+	if custom.enabled != nil {
+		return custom.enabled(settings)
 	}
 
-	return false
+	return true
+}
+
+// SettingsSpec returns the writer's package settings in the LOG envvar format.
+func (standardWriter *StandardWriter) SettingsSpec() string {
+	settingsMutex.RLock()
+	defer settingsMutex.RUnlock()
+
+	return formatPackageSettings(standardWriter.Settings)
 }
 
+// LoggerSettings returns the settings of the package p, capped by the Floor.
 func (standardWriter *StandardWriter) LoggerSettings(p string) *OutputSettings {
-	if settings, ok := standardWriter.Settings[p]; ok {
+	settingsMutex.RLock()
+	defer settingsMutex.RUnlock()
+
+	settings := standardWriter.packageSettings(p)
+
+	floor := standardWriter.Floor
+	if floor == nil || floor.Debug && floor.Info && floor.Timer && floor.Warn && floor.Error {
 		return settings
 	}
 
+	return &OutputSettings{
+		Debug: settings.Debug && floor.Debug,
+		Info:  settings.Info && floor.Info,
+		Timer: settings.Timer && floor.Timer,
+		Warn:  settings.Warn && floor.Warn,
+		Error: settings.Error && floor.Error,
+	}
+}
+
+// inheritedName returns the first of p and its parents that is configured.
+// Dotted names inherit the settings of their parents, e.g. "db.query" falls back
+// to "db" unless it's configured itself.
+func inheritedName(p string, configured func(name string) bool) (string, bool) {
+	for name := p; ; {
+		if configured(name) {
+			return name, true
+		}
+
+		dot := strings.LastIndexByte(name, '.')
+		if dot < 0 {
+			return "", false
+		}
+		name = name[:dot]
+	}
+}
+
+// packageSettings returns the configured settings of the package p. The caller
+// must hold settingsMutex.
+func (standardWriter *StandardWriter) packageSettings(p string) *OutputSettings {
+	name, ok := inheritedName(p, func(name string) bool {
+		_, ok := standardWriter.Settings[name]
+		return ok
+	})
+	if ok {
+		return standardWriter.Settings[name]
+	}
+
 	// If there is a "*" (Select all) setting, return that
 	if settings, ok := standardWriter.Settings["*"]; ok {
 		return settings
@@ -70,6 +411,17 @@ func (standardWriter *StandardWriter) LoggerSettings(p string) *OutputSettings {
 }
 
 func (standardWriter *StandardWriter) Format(log *Log) string {
+	switch standardWriter.OutputFormat {
+	case FormatPretty:
+		return standardWriter.PrettyFormat(log)
+	case FormatJSON:
+		return standardWriter.JSONFormat(log)
+	case FormatLogfmt:
+		return standardWriter.LogfmtFormat(log)
+	case FormatECS:
+		return standardWriter.ECSFormat(log)
+	}
+
 	if standardWriter.ColorsEnabled {
 		return standardWriter.PrettyFormat(log)
 	} else {
@@ -78,20 +430,104 @@ func (standardWriter *StandardWriter) Format(log *Log) string {
 }
 
 func (standardWriter *StandardWriter) JSONFormat(log *Log) string {
-	str, err := json.Marshal(log)
+	attrs := standardWriter.jsonAttrs(log.Attrs)
+
+	jsonTime := ""
+	if standardWriter.JSONTimeFormat != "" {
+		jsonTime = time.Unix(0, log.Time).Format(standardWriter.JSONTimeFormat)
+	}
+
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer bufferPool.Put(buf)
+
+	levelKey := standardWriter.levelKey()
+
+	if err := writeLogJSON(buf, log, attrs, jsonTime, levelKey); err == nil {
+		return buf.String()
+	}
+
+	formatted := *log
+	formatted.Attrs = attrs
+
+	var value interface{} = &formatted
+	if jsonTime != "" {
+		value = &struct {
+			*Log
+			Time string `json:"time"`
+		}{&formatted, jsonTime}
+	}
+
+	str, err := json.Marshal(value)
 	if err != nil {
 		return fmt.Sprintf(`{ "logger-error": "%v" }`, err)
 	}
 
+	if levelKey != "level" {
+		// The package comes first, and quotes within its value are escaped, so
+		// the first match is the level field.
+		key, _ := json.Marshal(levelKey)
+		str = bytes.Replace(str, []byte(`"level":`), append(key, ':'), 1)
+	}
+
 	return string(str)
 }
 
 func (standardWriter *StandardWriter) PrettyFormat(log *Log) string {
-	return fmt.Sprintf("%s %s %s%s",
-		time.Now().Format("15:04:05.000"),
-		standardWriter.PrettyLabel(log),
-		log.Message,
-		standardWriter.PrettyAttrs(log.Attrs))
+	sep := standardWriter.Separator
+	if sep == "" {
+		sep = " "
+	}
+
+	message, logAttrs := log.Message, log.Attrs
+	if highlighted(logAttrs) {
+		message = standardWriter.color(Bold) + message + standardWriter.color(Reset)
+
+		rest := mergeAttrs(nil, *logAttrs)
+		delete(rest, HighlightKey)
+		logAttrs = &rest
+	}
+
+	if log.Code != "" {
+		message = "[" + log.Code + "] " + message
+
+		// The code is already shown, no need to repeat its attribute.
+		if logAttrs != nil {
+			if _, ok := (*logAttrs)[ErrorCodeKey]; ok {
+				rest := mergeAttrs(nil, *logAttrs)
+				delete(rest, ErrorCodeKey)
+				logAttrs = &rest
+			}
+		}
+	}
+
+	attrs := ""
+	if !standardWriter.HideAttrs {
+		attrs = standardWriter.PrettyAttrs(logAttrs)
+	}
+
+	return fmt.Sprintf("%s%s%s%s%s%s",
+		logTime(log).Format(standardWriter.timeFormat()),
+		sep,
+		standardWriter.pad(standardWriter.PrettyLabel(log)),
+		sep,
+		message,
+		attrs)
+}
+
+// logTime is the time shown for the log: when it was written, or when the timer
+// ended, rather than when it reached the writer, which may be later for async or
+// buffered writers.
+func logTime(log *Log) time.Time {
+	if log.EndedAt != nil {
+		return *log.EndedAt
+	}
+
+	if log.Time != 0 {
+		return time.Unix(0, log.Time)
+	}
+
+	return time.Now()
 }
 
 func (standardWriter *StandardWriter) PrettyAttrs(attrs *Attrs) string {
@@ -99,32 +535,180 @@ func (standardWriter *StandardWriter) PrettyAttrs(attrs *Attrs) string {
 		return ""
 	}
 
-	result := ""
-	for key, val := range *attrs {
-		result = fmt.Sprintf("%s %s=%v", result, key, val)
+	limited, truncated := standardWriter.limitAttrs(*attrs)
+	values := standardWriter.attrMap(limited, false)
+
+	var b strings.Builder
+	var multiline []string
+	writeAttr := func(key string) {
+		value := prettyValue(values[key])
+		if standardWriter.MultilineAttrs && strings.Contains(value, "\n") {
+			multiline = append(multiline, key)
+			return
+		}
+
+		b.WriteByte(' ')
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(value)
+	}
+
+	if truncated == 0 {
+		for key := range values {
+			writeAttr(key)
+		}
+	} else {
+		for _, key := range sortedKeys(values) {
+			writeAttr(key)
+		}
+		fmt.Fprintf(&b, " …(+%d more)", truncated)
+	}
+
+	sort.Strings(multiline)
+	for _, key := range multiline {
+		fmt.Fprintf(&b, "\n    %s:", key)
+		for _, line := range strings.Split(strings.TrimRight(prettyValue(values[key]), "\n"), "\n") {
+			b.WriteString("\n      ")
+			b.WriteString(line)
+		}
+	}
+
+	return b.String()
+}
+
+// prettyValue formats the common attribute types with strconv, and the others
+// with "%v".
+func prettyValue(val interface{}) string {
+	switch v := val.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		var scratch [32]byte
+		return string(appendFloat(scratch[:0], v, 64))
+	}
+
+	return fmt.Sprintf("%v", val)
+}
+
+func (standardWriter *StandardWriter) levelKey() string {
+	if standardWriter.LevelKey == "" {
+		return "level"
+	}
+
+	return standardWriter.LevelKey
+}
+
+func (standardWriter *StandardWriter) timeFormat() string {
+	if standardWriter.TimeFormat == "" {
+		return DefaultTimeFormat
 	}
 
-	return result
+	return standardWriter.TimeFormat
 }
 
 func (standardWriter *StandardWriter) PrettyLabel(log *Log) string {
 	return fmt.Sprintf("%s%s%s:%s",
-		colorFor(log.Package),
+		standardWriter.color(standardWriter.packageColor(log.Package)),
 		log.Package,
 		standardWriter.PrettyLabelExt(log),
-		reset)
+		standardWriter.color(Reset))
+}
+
+func (standardWriter *StandardWriter) pad(label string) string {
+	width := visibleLen(label)
+	if width >= standardWriter.PadPackage {
+		return label
+	}
+
+	return label + strings.Repeat(" ", standardWriter.PadPackage-width)
+}
+
+// visibleLen returns the length of s as displayed by a terminal, not counting
+// ANSI escape sequences.
+func visibleLen(s string) int {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\033' {
+			for i < len(s) && s[i] != 'm' {
+				i++
+			}
+			continue
+		}
+
+		if utf8.RuneStart(s[i]) {
+			n++
+		}
+	}
+
+	return n
 }
 
 func (standardWriter *StandardWriter) PrettyLabelExt(log *Log) string {
 	if log.Level == "ERROR" {
-		return fmt.Sprintf("(%s!%s)", red, colorFor(log.Package))
+		return fmt.Sprintf("(%s!%s)", standardWriter.color(activeTheme().Error), standardWriter.color(standardWriter.packageColor(log.Package)))
 	}
 
 	if log.Level == "TIMER" {
-		return fmt.Sprintf("(%s%s%s)", reset, fmt.Sprintf("%v", time.Duration(log.ElapsedNano)), colorFor(log.Package))
+		elapsed := time.Duration(log.ElapsedNano)
+		if standardWriter.SlowThreshold > 0 && elapsed > standardWriter.SlowThreshold {
+			return fmt.Sprintf("(%s%v%s%s)", standardWriter.color(Bold+activeTheme().Error), elapsed, standardWriter.color(Reset), standardWriter.color(standardWriter.packageColor(log.Package)))
+		}
+
+		return fmt.Sprintf("(%s%s%s)", standardWriter.color(Reset), fmt.Sprintf("%v", elapsed), standardWriter.color(standardWriter.packageColor(log.Package)))
+	}
+
+	if log.Level == "WARN" {
+		return fmt.Sprintf("(%sWARN%s)", standardWriter.color(activeTheme().Warn), standardWriter.color(standardWriter.packageColor(log.Package)))
+	}
+
+	if log.Level == "INFO" || log.Level == "" {
+		return ""
 	}
 
-	return ""
+	color := Reset
+	if custom, ok := levels.Get(log.Level); ok && custom.color != "" {
+		color = custom.color
+	}
+
+	return fmt.Sprintf("(%s%s%s)", standardWriter.color(color), log.Level, standardWriter.color(standardWriter.packageColor(log.Package)))
+}
+
+func (standardWriter *StandardWriter) packageColor(pkg string) string {
+	if standardWriter.ColorFunc != nil {
+		return standardWriter.ColorFunc(pkg)
+	}
+
+	return colorFor(pkg)
+}
+
+// color returns the given ANSI escape, or nothing if colors are disabled.
+func (standardWriter *StandardWriter) color(escape string) string {
+	if !standardWriter.ColorsEnabled {
+		return ""
+	}
+
+	return escape
+}
+
+// isTerminal reports whether w is a file on a character device, e.g. a terminal.
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok || file == nil {
+		return false
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return stat.Mode()&os.ModeCharDevice != 0
 }
 
 // Accepts: foo,bar,qux@timer
@@ -133,10 +717,14 @@ func (standardWriter *StandardWriter) PrettyLabelExt(log *Log) string {
 //          *@error,database@timer
 func parsePackageSettings(input string, defaultOutputSettings *OutputSettings) map[string]*OutputSettings {
 	all := map[string]*OutputSettings{}
-	items := strings.Split(input, ",")
+	items := splitPackageSettings(input)
 
 	for _, item := range items {
 		name, verbosity := parsePackageName(item)
+		if name == "" {
+			continue
+		}
+
 		if verbosity == nil {
 			verbosity = defaultOutputSettings
 		}
@@ -147,18 +735,90 @@ func parsePackageSettings(input string, defaultOutputSettings *OutputSettings) m
 	return all
 }
 
+// splitPackageSettings splits the input on commas, except for those within quotes.
+func splitPackageSettings(input string) []string {
+	var items []string
+	var quote rune
+	start := 0
+
+	for i, c := range input {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			items = append(items, input[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(items, input[start:])
+}
+
+// formatPackageSettings is the inverse of parsePackageSettings.
+func formatPackageSettings(settings map[string]*OutputSettings) string {
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	items := make([]string, len(names))
+	for i, name := range names {
+		label := name
+		if strings.ContainsAny(name, ",@ ") {
+			label = strconv.Quote(name)
+		}
+
+		items[i] = label + "@" + settings[name].String()
+	}
+
+	return strings.Join(items, ",")
+}
+
 // Accepts: users
 //          database@timer
 //          server@error
+//          "foo bar"@info
 func parsePackageName(input string) (string, *OutputSettings) {
-	parsed := strings.Split(input, "@")
-	name := strings.TrimSpace(parsed[0])
+	input = strings.TrimSpace(input)
+
+	if len(input) > 1 && (input[0] == '"' || input[0] == '\'') {
+		if end := strings.IndexByte(input[1:], input[0]); end >= 0 {
+			name := input[1 : end+1]
+			rest := strings.TrimSpace(input[end+2:])
+
+			if strings.HasPrefix(rest, "@") {
+				return name, parseLevelSuffix(rest)
+			}
+
+			return name, nil
+		}
+	}
+
+	at := strings.IndexByte(input, '@')
+	if at < 0 {
+		return strings.TrimSpace(input), nil
+	}
 
-	if len(parsed) > 1 {
-		return name, parseVerbosityLevel(parsed[1])
+	return strings.TrimSpace(input[:at]), parseLevelSuffix(input[at:])
+}
+
+// parseLevelSuffix reads the level of an "@level" suffix. When it has several
+// segments, like "db@info@timer", the last non-empty one wins. An empty level,
+// like "db@", leaves the package at the default level.
+func parseLevelSuffix(suffix string) *OutputSettings {
+	segments := strings.Split(suffix, "@")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if level := strings.TrimSpace(segments[i]); level != "" {
+			return parseVerbosityLevel(level)
+		}
 	}
 
-	return name, nil
+	return nil
 }
 
 func parseVerbosityLevel(val string) *OutputSettings {
@@ -171,16 +831,27 @@ func parseVerbosityLevel(val string) *OutputSettings {
 	s := &OutputSettings{
 		Info:  true,
 		Timer: true,
+		Warn:  true,
 		Error: true,
 	}
 
+	if val == "DEBUG" {
+		s.Debug = true
+	}
+
 	if val == "TIMER" {
 		s.Info = false
 	}
 
+	if val == "WARN" || val == "WARNING" {
+		s.Info = false
+		s.Timer = false
+	}
+
 	if val == "ERROR" {
 		s.Info = false
 		s.Timer = false
+		s.Warn = false
 	}
 
 	return s
@@ -0,0 +1,28 @@
+//go:build go1.18
+// +build go1.18
+
+package logger
+
+import "testing"
+
+func FuzzParseSettings(f *testing.F) {
+	f.Add("*")
+	f.Add("*@error, database@timer")
+	f.Add(` *@error , database@timer `)
+	f.Add(`"a,b"@debug,'c'@,@info,db@info@@timer`)
+	f.Add(`"unterminated@warn,,@`)
+
+	defaults := &OutputSettings{Info: true, Timer: true, Warn: true, Error: true}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		for name, settings := range parsePackageSettings(input, defaults) {
+			if name == "" {
+				t.Errorf("parsePackageSettings(%q) returned an empty name", input)
+			}
+
+			if settings == nil {
+				t.Errorf("parsePackageSettings(%q) returned no settings for %q", input, name)
+			}
+		}
+	})
+}
@@ -0,0 +1,160 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParsePackageSettings(t *testing.T) {
+	defaults := &OutputSettings{Info: true, Timer: true, Warn: true, Error: true}
+
+	tests := []struct {
+		input string
+		want  map[string]string
+	}{
+		{"*@error,database@timer", map[string]string{"*": "error", "database": "timer"}},
+		{" *@error , database@timer ", map[string]string{"*": "error", "database": "timer"}},
+		{"\t* @ error ,\n database @timer\t", map[string]string{"*": "error", "database": "timer"}},
+		{` "foo bar"@info , 'a,b' @ debug `, map[string]string{"foo bar": "info", "a,b": "debug"}},
+		{" , ,db, @warn, ", map[string]string{"db": "info"}},
+		{"", map[string]string{}},
+	}
+
+	for _, test := range tests {
+		got := parsePackageSettings(test.input, defaults)
+
+		if len(got) != len(test.want) {
+			t.Errorf("parsePackageSettings(%q) = %v, want %v", test.input, got, test.want)
+			continue
+		}
+
+		for name, level := range test.want {
+			if settings, ok := got[name]; !ok || settings.String() != level {
+				t.Errorf("parsePackageSettings(%q)[%q] = %v, want %s", test.input, name, settings, level)
+			}
+		}
+	}
+}
+
+func TestPrettyValue(t *testing.T) {
+	tests := []struct {
+		val  interface{}
+		want string
+	}{
+		{true, "true"},
+		{false, "false"},
+		{0, "0"},
+		{-42, "-42"},
+		{int64(math.MaxInt64), "9223372036854775807"},
+		{"text", "text"},
+		{0.0, "0"},
+		{1.0, "1"},
+		{0.5, "0.5"},
+		{-2.25, "-2.25"},
+		{3.14159, "3.14159"},
+		{sum(0.1, 0.2), "0.30000000000000004"},
+		{123456789.0, "123456789"},
+		{1e20, "100000000000000000000"},
+		{1e21, "1e+21"},
+		{1e-6, "0.000001"},
+		{1e-7, "1e-7"},
+		{math.Copysign(0, -1), "-0"},
+		{math.NaN(), "NaN"},
+		{math.Inf(1), "+Inf"},
+		{float32(0.1), "0.1"},
+		{uint8(7), "7"},
+	}
+
+	for _, test := range tests {
+		if got := prettyValue(test.val); got != test.want {
+			t.Errorf("prettyValue(%#v) = %q, want %q", test.val, got, test.want)
+		}
+	}
+}
+
+func sum(a, b float64) float64 {
+	return a + b
+}
+
+var prettyValues = []interface{}{true, 42, int64(1) << 40, 0.25, 1234.5678, "text"}
+
+func BenchmarkPrettyValue(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, val := range prettyValues {
+			prettyValue(val)
+		}
+	}
+}
+
+func BenchmarkPrettyValueSprintf(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, val := range prettyValues {
+			_ = fmt.Sprintf("%v", val)
+		}
+	}
+}
+
+// unsetEnv unsets the given envvars for the duration of the test.
+func unsetEnv(t *testing.T, names ...string) {
+	for _, name := range names {
+		if value, ok := os.LookupEnv(name); ok {
+			os.Unsetenv(name)
+			t.Cleanup(func() { os.Setenv(name, value) })
+		}
+	}
+}
+
+func TestNewStandardOutputWriterBuffer(t *testing.T) {
+	unsetEnv(t, "LOG", "LOG_JSON", "LOG_LEVEL", "LOG_FORMAT")
+
+	var buf bytes.Buffer
+	writer := NewStandardOutputWriter(&buf)
+
+	standardWriter, ok := writer.(StandardWriter)
+	if !ok {
+		t.Fatalf("got a %T, want a StandardWriter", writer)
+	}
+
+	if standardWriter.ColorsEnabled || standardWriter.OutputFormat != FormatJSON {
+		t.Errorf("colors = %v and format = %q for a buffer, want no colors and JSON", standardWriter.ColorsEnabled, standardWriter.OutputFormat)
+	}
+
+	writer.Write(&Log{Package: "test", Level: "INFO", Message: "to a buffer", Time: 1})
+	writer.Write(&Log{Package: "test", Level: "ERROR", Message: "failed", Time: 2})
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %q", len(lines), buf.String())
+	}
+
+	for _, line := range lines {
+		if strings.Contains(line, "\x1b[") {
+			t.Errorf("line %q contains color codes", line)
+		}
+
+		var log Log
+		if err := json.Unmarshal([]byte(line), &log); err != nil {
+			t.Errorf("line %q isn't JSON: %v", line, err)
+		}
+	}
+}
+
+func TestNewStandardOutputWriterPrettyBuffer(t *testing.T) {
+	unsetEnv(t, "LOG", "LOG_JSON", "LOG_LEVEL", "LOG_FORMAT")
+	os.Setenv("LOG_FORMAT", "pretty")
+	defer os.Unsetenv("LOG_FORMAT")
+
+	var buf bytes.Buffer
+	NewStandardOutputWriter(&buf).Write(&Log{Package: "test", Level: "ERROR", Message: "failed", Time: 1})
+
+	if !strings.Contains(buf.String(), "failed") || strings.Contains(buf.String(), "\x1b[") {
+		t.Errorf("pretty output %q to a buffer has color codes", buf.String())
+	}
+}
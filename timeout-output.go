@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Resetter is implemented by writers holding a connection that can be reset,
+// e.g. network writers whose peer has stalled.
+type Resetter interface {
+	Reset() error
+}
+
+// TimeoutOptions configures a TimeoutWriter.
+type TimeoutOptions struct {
+	// Timeout bounds each write. Defaults to one second.
+	Timeout time.Duration
+
+	// ResetAfter is the number of consecutive timeouts after which the inner
+	// writer is reset, if it implements Resetter. Defaults to 3.
+	ResetAfter int
+}
+
+// TimeoutWriter keeps a slow inner writer, typically a network one, from blocking
+// the program. Logs that can't be handed over to the inner writer within the
+// timeout are dropped; logs handed over whose write doesn't complete in time are
+// left pending. The inner writer is reset after repeated timeouts.
+type TimeoutWriter struct {
+	inner    OutputWriter
+	opts     TimeoutOptions
+	queue    chan timeoutItem
+	reset    chan struct{}
+	stop     chan struct{}
+	stopped  chan struct{}
+	close    sync.Once
+	dropped  uint64
+	pending  uint64
+	timeouts int64
+}
+
+type timeoutItem struct {
+	log  *Log
+	done chan struct{}
+}
+
+// NewTimeoutWriter wraps inner with a per-write timeout.
+func NewTimeoutWriter(inner OutputWriter, opts TimeoutOptions) *TimeoutWriter {
+	if opts.Timeout <= 0 {
+		opts.Timeout = time.Second
+	}
+
+	if opts.ResetAfter <= 0 {
+		opts.ResetAfter = 3
+	}
+
+	timeoutWriter := &TimeoutWriter{
+		inner:   inner,
+		opts:    opts,
+		queue:   make(chan timeoutItem),
+		reset:   make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	go timeoutWriter.run()
+
+	return timeoutWriter
+}
+
+func (timeoutWriter *TimeoutWriter) Init() {
+	timeoutWriter.inner.Init()
+}
+
+// Write hands a copy of the log over to the inner writer. Logs written after
+// Close are dropped.
+func (timeoutWriter *TimeoutWriter) Write(log *Log) {
+	timer := time.NewTimer(timeoutWriter.opts.Timeout)
+	defer timer.Stop()
+
+	item := timeoutItem{log: log.Clone(), done: make(chan struct{}, 1)}
+
+	select {
+	case timeoutWriter.queue <- item:
+	case <-timeoutWriter.stop:
+		atomic.AddUint64(&timeoutWriter.dropped, 1)
+		return
+	case <-timer.C:
+		atomic.AddUint64(&timeoutWriter.dropped, 1)
+		timeoutWriter.timedOut()
+		return
+	}
+
+	select {
+	case <-item.done:
+		atomic.StoreInt64(&timeoutWriter.timeouts, 0)
+	case <-timer.C:
+		atomic.AddUint64(&timeoutWriter.pending, 1)
+		timeoutWriter.timedOut()
+	}
+}
+
+// Dropped returns the number of logs that were never written because the inner
+// writer didn't take them within the timeout, or because the writer was closed.
+func (timeoutWriter *TimeoutWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&timeoutWriter.dropped)
+}
+
+// Pending returns the number of logs whose write timed out after they were
+// handed over. The inner writer may still complete these writes.
+func (timeoutWriter *TimeoutWriter) Pending() uint64 {
+	return atomic.LoadUint64(&timeoutWriter.pending)
+}
+
+// Close stops the background goroutine and, once it has returned, closes the
+// inner writer if it implements io.Closer. It gives up after the timeout if the
+// inner writer is still stuck in a write, without closing it.
+func (timeoutWriter *TimeoutWriter) Close() error {
+	timeoutWriter.close.Do(func() {
+		close(timeoutWriter.stop)
+	})
+
+	timer := time.NewTimer(timeoutWriter.opts.Timeout)
+	defer timer.Stop()
+
+	select {
+	case <-timeoutWriter.stopped:
+	case <-timer.C:
+		return errors.New("logger: timed out waiting for the inner writer")
+	}
+
+	if closer, ok := timeoutWriter.inner.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// timedOut counts a consecutive timeout and, once ResetAfter is reached, asks
+// run to reset the inner writer between two writes.
+func (timeoutWriter *TimeoutWriter) timedOut() {
+	if atomic.AddInt64(&timeoutWriter.timeouts, 1) < int64(timeoutWriter.opts.ResetAfter) {
+		return
+	}
+
+	if _, ok := timeoutWriter.inner.(Resetter); !ok {
+		return
+	}
+
+	atomic.StoreInt64(&timeoutWriter.timeouts, 0)
+
+	select {
+	case timeoutWriter.reset <- struct{}{}:
+	default:
+	}
+}
+
+func (timeoutWriter *TimeoutWriter) run() {
+	defer close(timeoutWriter.stopped)
+
+	for {
+		select {
+		case item := <-timeoutWriter.queue:
+			timeoutWriter.inner.Write(item.log)
+			item.done <- struct{}{}
+		case <-timeoutWriter.reset:
+			timeoutWriter.inner.(Resetter).Reset()
+		case <-timeoutWriter.stop:
+			return
+		}
+	}
+}
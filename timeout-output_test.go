@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingWriter blocks every write until release is closed, and counts resets.
+type blockingWriter struct {
+	release chan struct{}
+
+	mutex   sync.Mutex
+	written int
+	resets  int
+	writing bool
+	overlap bool
+}
+
+func (blockingWriter *blockingWriter) Init() {}
+
+func (blockingWriter *blockingWriter) Write(log *Log) {
+	blockingWriter.mutex.Lock()
+	blockingWriter.writing = true
+	blockingWriter.mutex.Unlock()
+
+	<-blockingWriter.release
+
+	blockingWriter.mutex.Lock()
+	blockingWriter.writing = false
+	blockingWriter.written++
+	blockingWriter.mutex.Unlock()
+}
+
+func (blockingWriter *blockingWriter) Reset() error {
+	blockingWriter.mutex.Lock()
+	defer blockingWriter.mutex.Unlock()
+
+	blockingWriter.overlap = blockingWriter.overlap || blockingWriter.writing
+	blockingWriter.resets++
+	return nil
+}
+
+func TestTimeoutWriter(t *testing.T) {
+	inner := &blockingWriter{release: make(chan struct{})}
+	timeoutWriter := NewTimeoutWriter(inner, TimeoutOptions{Timeout: 10 * time.Millisecond, ResetAfter: 2})
+
+	// The first log is handed over and left pending; the second can't be.
+	timeoutWriter.Write(&Log{Message: "pending"})
+	timeoutWriter.Write(&Log{Message: "dropped"})
+
+	if pending, dropped := timeoutWriter.Pending(), timeoutWriter.Dropped(); pending != 1 || dropped != 1 {
+		t.Errorf("pending = %d, dropped = %d, want 1 and 1", pending, dropped)
+	}
+
+	close(inner.release)
+
+	if err := timeoutWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	inner.mutex.Lock()
+	defer inner.mutex.Unlock()
+
+	if inner.written != 1 {
+		t.Errorf("written = %d, want the pending log written", inner.written)
+	}
+
+	if inner.overlap {
+		t.Error("the inner writer was reset during a write")
+	}
+
+	timeoutWriter.Write(&Log{Message: "closed"})
+	if dropped := timeoutWriter.Dropped(); dropped != 2 {
+		t.Errorf("dropped = %d after Close, want 2", dropped)
+	}
+}
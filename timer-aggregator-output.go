@@ -0,0 +1,187 @@
+package logger
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TimerAggregatorOptions configures a TimerAggregator.
+type TimerAggregatorOptions struct {
+	// Interval is how often summaries are emitted. Defaults to one minute.
+	Interval time.Duration
+
+	// Samples bounds the durations kept per package for estimating percentiles.
+	// Past it, a uniform sample of the timers is kept. Defaults to 1024.
+	Samples int
+
+	// Suppress drops the individual TIMER logs, leaving only the summaries.
+	Suppress bool
+}
+
+// TimerAggregator forwards logs to an inner writer while accumulating the
+// durations of TIMER logs per package, and periodically emits a summary log for
+// each package with the count, min, max, p50 and p95 of its timers.
+type TimerAggregator struct {
+	inner OutputWriter
+	opts  TimerAggregatorOptions
+
+	mutex  sync.Mutex
+	timers map[string]*timerStats
+	random *rand.Rand
+	stop   chan struct{}
+	once   sync.Once
+}
+
+type timerStats struct {
+	count   int
+	min     int64
+	max     int64
+	samples []int64
+}
+
+// NewTimerAggregator wraps inner with timer summaries, emitted until Close is
+// called.
+func NewTimerAggregator(inner OutputWriter, opts TimerAggregatorOptions) *TimerAggregator {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Minute
+	}
+
+	if opts.Samples <= 0 {
+		opts.Samples = 1024
+	}
+
+	timerAggregator := &TimerAggregator{
+		inner:  inner,
+		opts:   opts,
+		timers: map[string]*timerStats{},
+		random: rand.New(rand.NewSource(time.Now().UnixNano())),
+		stop:   make(chan struct{}),
+	}
+
+	go timerAggregator.run()
+
+	return timerAggregator
+}
+
+func (timerAggregator *TimerAggregator) Init() {
+	timerAggregator.inner.Init()
+}
+
+func (timerAggregator *TimerAggregator) Write(log *Log) {
+	if log.Level != "TIMER" {
+		timerAggregator.inner.Write(log)
+		return
+	}
+
+	timerAggregator.add(log.Package, log.ElapsedNano)
+
+	if !timerAggregator.opts.Suppress {
+		timerAggregator.inner.Write(log)
+	}
+}
+
+func (timerAggregator *TimerAggregator) add(pkg string, elapsed int64) {
+	timerAggregator.mutex.Lock()
+	defer timerAggregator.mutex.Unlock()
+
+	stats, ok := timerAggregator.timers[pkg]
+	if !ok {
+		stats = &timerStats{min: elapsed, max: elapsed}
+		timerAggregator.timers[pkg] = stats
+	}
+
+	stats.count++
+	if elapsed < stats.min {
+		stats.min = elapsed
+	}
+	if elapsed > stats.max {
+		stats.max = elapsed
+	}
+
+	// Reservoir sampling keeps every timer equally likely to be in the sample.
+	if len(stats.samples) < timerAggregator.opts.Samples {
+		stats.samples = append(stats.samples, elapsed)
+	} else if i := timerAggregator.random.Intn(stats.count); i < len(stats.samples) {
+		stats.samples[i] = elapsed
+	}
+}
+
+func (timerAggregator *TimerAggregator) run() {
+	ticker := time.NewTicker(timerAggregator.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			timerAggregator.Flush()
+		case <-timerAggregator.stop:
+			return
+		}
+	}
+}
+
+// Flush emits the summaries of the timers accumulated so far and starts over.
+func (timerAggregator *TimerAggregator) Flush() error {
+	timerAggregator.mutex.Lock()
+	timers := timerAggregator.timers
+	timerAggregator.timers = map[string]*timerStats{}
+	timerAggregator.mutex.Unlock()
+
+	pkgs := make([]string, 0, len(timers))
+	for pkg := range timers {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+
+	for _, pkg := range pkgs {
+		stats := timers[pkg]
+		sort.Slice(stats.samples, func(i, j int) bool {
+			return stats.samples[i] < stats.samples[j]
+		})
+
+		emit(timerAggregator.inner, &Log{
+			Package: pkg,
+			Level:   "INFO",
+			Message: "Timer summary",
+			Time:    Now(),
+			Attrs: &Attrs{
+				"count": stats.count,
+				"min":   time.Duration(stats.min),
+				"max":   time.Duration(stats.max),
+				"p50":   time.Duration(percentile(stats.samples, 0.50)),
+				"p95":   time.Duration(percentile(stats.samples, 0.95)),
+			},
+		})
+	}
+
+	return nil
+}
+
+// Close emits the pending summaries and stops the periodic ones.
+func (timerAggregator *TimerAggregator) Close() error {
+	timerAggregator.once.Do(func() {
+		close(timerAggregator.stop)
+	})
+
+	return timerAggregator.Flush()
+}
+
+// percentile returns the nearest-rank percentile of sorted samples.
+func percentile(sorted []int64, p float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+
+	return sorted[i]
+}
@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	sequence := func(n int) []int64 {
+		sorted := make([]int64, n)
+		for i := range sorted {
+			sorted[i] = int64(i + 1)
+		}
+		return sorted
+	}
+
+	tests := []struct {
+		n    int
+		p    float64
+		want int64
+	}{
+		{0, 0.5, 0},
+		{1, 0.5, 1},
+		{1, 0.95, 1},
+		{3, 0.5, 2},
+		{4, 0.5, 2},
+		{10, 0.95, 10},
+		{13, 0.95, 13},
+		{20, 0.95, 19},
+		{100, 0.5, 50},
+		{100, 0.95, 95},
+	}
+
+	for _, test := range tests {
+		if got := percentile(sequence(test.n), test.p); got != test.want {
+			t.Errorf("percentile(1..%d, %v) = %d, want %d", test.n, test.p, got, test.want)
+		}
+	}
+}
+
+func TestTimerAggregator(t *testing.T) {
+	tests := []struct {
+		name     string
+		suppress bool
+		written  int
+	}{
+		{"Forward", false, 5},
+		{"Suppress", true, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := &recordWriter{}
+			timerAggregator := NewTimerAggregator(recorder, TimerAggregatorOptions{Interval: time.Hour, Suppress: test.suppress})
+
+			for _, elapsed := range []time.Duration{3, 1, 4, 2} {
+				timerAggregator.Write(&Log{Package: "db", Level: "TIMER", ElapsedNano: int64(elapsed * time.Millisecond)})
+			}
+			timerAggregator.Write(&Log{Package: "db", Level: "INFO", Message: "not a timer"})
+
+			if len(recorder.logs) != test.written {
+				t.Fatalf("forwarded %d logs, want %d", len(recorder.logs), test.written)
+			}
+
+			if err := timerAggregator.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			summary := recorder.logs[len(recorder.logs)-1]
+			if summary.Message != "Timer summary" || summary.Package != "db" {
+				t.Fatalf("last log is %+v, want the summary of db", summary)
+			}
+
+			want := Attrs{
+				"count": 4,
+				"min":   time.Millisecond,
+				"max":   4 * time.Millisecond,
+				"p50":   2 * time.Millisecond,
+				"p95":   4 * time.Millisecond,
+			}
+			for key, value := range want {
+				if got := (*summary.Attrs)[key]; got != value {
+					t.Errorf("%s = %v, want %v", key, got, value)
+				}
+			}
+
+			// The timers were reset by the summary.
+			timerAggregator.Flush()
+			if last := recorder.logs[len(recorder.logs)-1]; last != summary {
+				t.Error("a second summary was emitted without new timers")
+			}
+		})
+	}
+}